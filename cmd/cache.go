@@ -0,0 +1,56 @@
+/*
+Copyright © 2025 Pato Diaz pato@patodiaz.io
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// cacheCmd represents the cache command
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manages the cache of model details",
+	Long: `Manages the cache of model details
+
+The details /api/show returns are kept on disk, keyed by the model digest, so listing
+models only asks the server for the ones that are new or changed. Use --no-cache to
+skip it for a run.`,
+}
+
+// cacheClearCmd represents the cache clear command
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Removes every cached model",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		c := newCache(s)
+
+		removed, err := c.Clear()
+		if err != nil {
+			fmt.Printf("clearing cache: %+v\n", err)
+			return
+		}
+		fmt.Printf("Removed %d models from %s\n", removed, c.Dir())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+}
@@ -0,0 +1,78 @@
+/*
+Copyright © 2025 Pato Diaz pato@patodiaz.io
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/padiazg/ollama-tools/internals/chat"
+	"github.com/spf13/cobra"
+)
+
+// chatCmd represents the chat command
+var chatCmd = &cobra.Command{
+	Use:   "chat <model-name>",
+	Short: "Chats with a model, for quick smoke tests",
+	Long: `Chats with a model, for quick smoke tests
+
+Replies are streamed as they are generated, followed by the tokens generated, the
+speed in tokens per second and how much of the context window is left. Type /? for
+the available commands: /system, /set, /save, /load, /clear and /bye.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var (
+			system  string
+			num_ctx int
+			err     error
+		)
+
+		if system, err = cmd.Flags().GetString("system"); err != nil {
+			fmt.Printf("getting system flag: %+v", err)
+			return
+		}
+
+		if num_ctx, err = cmd.Flags().GetInt("num-ctx"); err != nil {
+			fmt.Printf("getting num-ctx flag: %+v", err)
+			return
+		}
+
+		client, err := newClient()
+		if err != nil {
+			fmt.Printf("%+v\n", err)
+			return
+		}
+		defer client.Close()
+
+		session := chat.NewSession(client, args[0], os.Stdout)
+		session.System = system
+		if num_ctx > 0 {
+			session.Options["num_ctx"] = num_ctx
+		}
+
+		if err := session.Run(cmd.Context(), os.Stdin); err != nil {
+			fmt.Printf("chat: %+v\n", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(chatCmd)
+
+	chatCmd.Flags().String("system", "", "System message")
+	chatCmd.Flags().IntP("num-ctx", "c", 0, "Context window (num_ctx), the model default if not set")
+}
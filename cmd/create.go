@@ -0,0 +1,76 @@
+/*
+Copyright © 2025 Pato Diaz pato@patodiaz.io
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/padiazg/ollama-tools/internals/models"
+	"github.com/spf13/cobra"
+)
+
+// createCmd represents the create command
+var createCmd = &cobra.Command{
+	Use:   "create <model-name>",
+	Short: "Creates a model from a Modelfile using the Ollama api",
+	Long: `Creates a model from a Modelfile using the Ollama api
+
+Before building, the memory needed is estimated with the num_ctx and num_gpu
+PARAMETERs of the Modelfile and checked against the configured hardware profile.
+Local FROM and ADAPTER files are uploaded when the server doesn't have them yet.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var (
+			file     string
+			quantize string
+			dry_run  bool
+			err      error
+		)
+
+		if file, err = cmd.Flags().GetString("file"); err != nil {
+			fmt.Printf("getting file flag: %+v", err)
+			return
+		}
+
+		if quantize, err = cmd.Flags().GetString("quantize"); err != nil {
+			fmt.Printf("getting quantize flag: %+v", err)
+			return
+		}
+
+		if dry_run, err = cmd.Flags().GetBool("dry-run"); err != nil {
+			fmt.Printf("getting dry-run flag: %+v", err)
+			return
+		}
+
+		client, err := newClient()
+		if err != nil {
+			fmt.Printf("%+v\n", err)
+			return
+		}
+		defer client.Close()
+
+		models.Create(cmd.Context(), client, s.Hardware, args[0], file, quantize, dry_run)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(createCmd)
+
+	createCmd.Flags().StringP("file", "f", "Modelfile", "Path to the Modelfile")
+	createCmd.Flags().StringP("quantize", "q", "", "Quantize the model to this level (q4_K_M, q8_0, ...)")
+	createCmd.Flags().BoolP("dry-run", "n", false, "Only print the estimate")
+}
@@ -0,0 +1,124 @@
+/*
+Copyright © 2025 Pato Diaz pato@patodiaz.io
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/padiazg/ollama-tools/internals/embed"
+	"github.com/spf13/cobra"
+)
+
+// embedCmd represents the embed command
+var embedCmd = &cobra.Command{
+	Use:   "embed <model-name> [text]...",
+	Short: "Creates embeddings for texts, files or a JSONL/CSV column",
+	Long: `Creates embeddings for texts, files or a JSONL/CSV column
+
+Every text argument and every --file is an input, and so is every row of the --column
+in the --input JSONL or CSV file (CSV files need a header row). Inputs are sent in
+batches, several at a time, and the vectors are written in input order as JSON lines,
+CSV rows or raw little-endian float32 values.
+
+The dimensions, norms and throughput are reported to stderr, and the dimensions are
+checked against the embedding_length of the model.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var (
+			src    = &embed.Source{Texts: args[1:]}
+			cfg    = &embed.Config{}
+			output string
+			out    io.Writer = os.Stdout
+			err    error
+		)
+
+		if src.Files, err = cmd.Flags().GetStringSlice("file"); err != nil {
+			fmt.Printf("getting file flag: %+v", err)
+			return
+		}
+
+		if src.Table, err = cmd.Flags().GetString("input"); err != nil {
+			fmt.Printf("getting input flag: %+v", err)
+			return
+		}
+
+		if src.Column, err = cmd.Flags().GetString("column"); err != nil {
+			fmt.Printf("getting column flag: %+v", err)
+			return
+		}
+
+		if cfg.BatchSize, err = cmd.Flags().GetInt("batch-size"); err != nil {
+			fmt.Printf("getting batch-size flag: %+v", err)
+			return
+		}
+
+		if cfg.Concurrency, err = cmd.Flags().GetInt("concurrency"); err != nil {
+			fmt.Printf("getting concurrency flag: %+v", err)
+			return
+		}
+
+		if cfg.Format, err = cmd.Flags().GetString("format"); err != nil {
+			fmt.Printf("getting format flag: %+v", err)
+			return
+		}
+
+		if err := embed.CheckFormat(cfg.Format); err != nil {
+			fmt.Fprintf(os.Stderr, "embed: %+v\n", err)
+			return
+		}
+
+		if output, err = cmd.Flags().GetString("output"); err != nil {
+			fmt.Printf("getting output flag: %+v", err)
+			return
+		}
+
+		if output != "" && output != "-" {
+			f, err := os.Create(output)
+			if err != nil {
+				fmt.Printf("creating %s: %+v\n", output, err)
+				return
+			}
+			defer f.Close()
+			out = f
+		}
+
+		client, err := newClient()
+		if err != nil {
+			fmt.Printf("%+v\n", err)
+			return
+		}
+		defer client.Close()
+
+		if err := embed.Run(cmd.Context(), client, args[0], src, cfg, out, os.Stderr); err != nil {
+			fmt.Fprintf(os.Stderr, "embed: %+v\n", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(embedCmd)
+
+	embedCmd.Flags().StringSlice("file", nil, "Files to embed, each file is one input")
+	embedCmd.Flags().StringP("input", "i", "", "JSONL or CSV file with one input per row")
+	embedCmd.Flags().String("column", "", "Column or field of --input to embed")
+	embedCmd.Flags().IntP("batch-size", "b", embed.DefaultBatchSize, "Inputs per request")
+	embedCmd.Flags().IntP("concurrency", "c", embed.DefaultConcurrency, "Requests at the same time")
+	embedCmd.Flags().StringP("format", "f", embed.FormatJSONL, "Output format: jsonl, csv or f32")
+	embedCmd.Flags().StringP("output", "o", "", "Output file, stdout if not set")
+}
@@ -5,21 +5,29 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 
+	"github.com/padiazg/ollama-tools/internals/models"
 	"github.com/padiazg/ollama-tools/internals/tools"
 	"github.com/spf13/cobra"
 )
 
 // estimateCmd represents the estimate command
 var estimateCmd = &cobra.Command{
-	Use:   "estimate",
+	Use:   "estimate [model]",
 	Short: "Estimates the RAM requirement based on few paramaters ",
-	Long:  `Estimates the RAM rwquirement based on few parameters without the need to download any model`,
+	Long: `Estimates the RAM rwquirement based on few parameters without the need to download any model
+
+Given a model served by the backend, its parameter count and quantization level are
+read from the server and the context defaults to the one the server runs it with.
+The flags override what the server reports.`,
+	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		var (
 			parameter_count    int64
 			context_length     int
 			quantization_level string
+			as_chart           bool
 			err                error
 		)
 
@@ -41,6 +49,52 @@ var estimateCmd = &cobra.Command{
 			return
 		}
 
+		as_chart, err = cmd.Flags().GetBool("chart")
+		if err != nil {
+			fmt.Printf("getting chart: %+v", err)
+			return
+		}
+
+		name := ""
+		if len(args) > 0 {
+			backend := newBackend()
+			defer backend.Close()
+
+			est, err := models.EstimateModel(cmd.Context(), backend, args[0], context_length, quantization_level)
+			if err != nil {
+				fmt.Printf("estimating %s: %+v\n", args[0], err)
+				return
+			}
+
+			if !as_chart {
+				models.PrintModelEstimate(est)
+				return
+			}
+
+			name = est.Name
+			parameter_count = est.Model.ModelInfo.ParameterCount
+			context_length = est.ContextLength
+			quantization_level = est.QuantizationLevel
+		} else if parameter_count == 0 || context_length == 0 || quantization_level == "" {
+			fmt.Println("parameter-count, context-length and quantization-level are needed without a model")
+			return
+		}
+
+		if as_chart {
+			if name == "" {
+				name = tools.FormatParamCount(parameter_count) + " " + quantization_level
+			}
+
+			entries := []tools.MemoryChartEntry{{
+				Name:              name,
+				ParameterCount:    parameter_count,
+				ContextLength:     context_length,
+				QuantizationLevel: quantization_level,
+			}}
+			tools.RenderChart(os.Stdout, tools.MemoryChart(entries, s.Hardware.VRAM, s.Hardware.RAM))
+			return
+		}
+
 		mem := tools.EstimateMemory(parameter_count, context_length, quantization_level)
 		tools.PrintEstimatedMemoryPlain(mem)
 	},
@@ -61,7 +115,5 @@ func init() {
 	estimateCmd.Flags().Int64P("parameter-count", "p", 0, "Parameters count")
 	estimateCmd.Flags().IntP("context-length", "c", 0, "Context length")
 	estimateCmd.Flags().StringP("quantization-level", "q", "", "Quantization level")
-	estimateCmd.MarkFlagRequired("parameter-count")
-	estimateCmd.MarkFlagRequired("context-length")
-	estimateCmd.MarkFlagRequired("quantization-level")
+	estimateCmd.Flags().Bool("chart", false, "Chart GPU and system RAM against context length")
 }
@@ -0,0 +1,59 @@
+/*
+Copyright © 2025 Pato Diaz pato@patodiaz.io
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/padiazg/ollama-tools/internals/models"
+	"github.com/spf13/cobra"
+)
+
+// estimateCreateCmd represents the estimate create command
+var estimateCreateCmd = &cobra.Command{
+	Use:   "create <source>",
+	Short: "Estimates disk and RAM needed to quantize a model with ollama create",
+	Long: `Estimates disk and RAM needed to quantize a model with ollama create --quantize
+
+The source can be an installed model name, a GGUF file, a safetensors file or a
+directory with safetensors shards. Reports the peak disk usage (source plus output
+blobs), the peak RAM during conversion and the final size.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		quantize, err := cmd.Flags().GetString("quantize")
+		if err != nil {
+			fmt.Printf("getting quantize flag: %+v", err)
+			return
+		}
+
+		client, err := newClient()
+		if err != nil {
+			fmt.Printf("%+v\n", err)
+			return
+		}
+		defer client.Close()
+
+		models.EstimateCreate(cmd.Context(), client, args[0], quantize)
+	},
+}
+
+func init() {
+	estimateCmd.AddCommand(estimateCreateCmd)
+
+	estimateCreateCmd.Flags().StringP("quantize", "q", "", "Target quantization level (q4_K_M, q8_0, ...)")
+	estimateCreateCmd.MarkFlagRequired("quantize")
+}
@@ -0,0 +1,164 @@
+/*
+Copyright © 2025 Pato Diaz pato@patodiaz.io
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/padiazg/ollama-tools/internals/models"
+	"github.com/padiazg/ollama-tools/internals/tools"
+	"github.com/padiazg/ollama-tools/models/ollama"
+	"github.com/spf13/cobra"
+)
+
+// estimateFinetuneCmd represents the estimate finetune command
+var estimateFinetuneCmd = &cobra.Command{
+	Use:   "finetune [model-name]",
+	Short: "Estimates the GPU memory needed to fine-tune a model with LoRA/QLoRA",
+	Long: `Estimates the GPU memory needed to fine-tune a model with LoRA/QLoRA
+
+Reports the frozen base weights, the adapter parameters for the given rank and
+target modules, optimizer state, gradients and activations for the batch size and
+sequence length, and the size of the resulting ADAPTER layer once loaded into Ollama.
+
+If model-name is given the parameter count, quantization and architecture are taken
+from the installed model, flags override them. Use -q Q4 for QLoRA.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var (
+			model_name     string
+			target_modules string
+			ft             = &tools.FinetuneConfig{}
+			err            error
+		)
+
+		if len(args) > 0 {
+			model_name = args[0]
+		}
+
+		if ft.ParameterCount, err = cmd.Flags().GetInt64("parameter-count"); err != nil {
+			fmt.Printf("getting parameter-count: %+v", err)
+			return
+		}
+
+		if ft.QuantizationLevel, err = cmd.Flags().GetString("quantization-level"); err != nil {
+			fmt.Printf("getting quantization-level: %+v", err)
+			return
+		}
+
+		if ft.Rank, err = cmd.Flags().GetInt("rank"); err != nil {
+			fmt.Printf("getting rank: %+v", err)
+			return
+		}
+
+		if target_modules, err = cmd.Flags().GetString("target-modules"); err != nil {
+			fmt.Printf("getting target-modules: %+v", err)
+			return
+		}
+
+		if ft.TargetModules, err = tools.ParseTargetModules(target_modules); err != nil {
+			fmt.Printf("parsing target-modules: %+v", err)
+			return
+		}
+
+		if ft.BatchSize, err = cmd.Flags().GetInt("batch-size"); err != nil {
+			fmt.Printf("getting batch-size: %+v", err)
+			return
+		}
+
+		if ft.SequenceLength, err = cmd.Flags().GetInt("sequence-length"); err != nil {
+			fmt.Printf("getting sequence-length: %+v", err)
+			return
+		}
+
+		if ft.GradientCheckpointing, err = cmd.Flags().GetBool("gradient-checkpointing"); err != nil {
+			fmt.Printf("getting gradient-checkpointing: %+v", err)
+			return
+		}
+
+		if ft.Architecture, err = architectureFlags(cmd); err != nil {
+			fmt.Printf("getting architecture flags: %+v", err)
+			return
+		}
+
+		if model_name == "" && ft.ParameterCount == 0 {
+			fmt.Println("either a model-name or --parameter-count is required")
+			return
+		}
+
+		client, err := newClient()
+		if err != nil {
+			fmt.Printf("%+v\n", err)
+			return
+		}
+		defer client.Close()
+
+		models.EstimateFinetune(cmd.Context(), client, model_name, ft)
+	},
+}
+
+// architectureFlags reads the optional flags overriding the model architecture
+func architectureFlags(cmd *cobra.Command) (ollama.ModelInfo, error) {
+	var (
+		arch            = ollama.ModelInfo{}
+		ff, heads, kvhs int
+		err             error
+	)
+
+	if arch.BlockCount, err = cmd.Flags().GetInt("layers"); err != nil {
+		return arch, err
+	}
+
+	if arch.EmbeddingLength, err = cmd.Flags().GetInt("hidden-size"); err != nil {
+		return arch, err
+	}
+
+	if ff, err = cmd.Flags().GetInt("ff-length"); err != nil {
+		return arch, err
+	}
+
+	if heads, err = cmd.Flags().GetInt("heads"); err != nil {
+		return arch, err
+	}
+
+	if kvhs, err = cmd.Flags().GetInt("kv-heads"); err != nil {
+		return arch, err
+	}
+
+	arch.FeedForwardLength = ollama.FlexInt(ff)
+	arch.HeadCount = ollama.FlexInt(heads)
+	arch.HeadCountKV = ollama.FlexInt(kvhs)
+
+	return arch, nil
+}
+
+func init() {
+	estimateCmd.AddCommand(estimateFinetuneCmd)
+
+	estimateFinetuneCmd.Flags().Int64P("parameter-count", "p", 0, "Parameters count")
+	estimateFinetuneCmd.Flags().StringP("quantization-level", "q", "", "Quantization level of the frozen base model (default F16)")
+	estimateFinetuneCmd.Flags().IntP("rank", "r", 16, "LoRA rank")
+	estimateFinetuneCmd.Flags().StringP("target-modules", "m", "q,v", "LoRA target modules: q,k,v,o,gate,up,down or all")
+	estimateFinetuneCmd.Flags().IntP("batch-size", "b", 1, "Batch size")
+	estimateFinetuneCmd.Flags().IntP("sequence-length", "s", 2048, "Sequence length")
+	estimateFinetuneCmd.Flags().Bool("gradient-checkpointing", false, "Keep only layer inputs and recompute activations")
+	estimateFinetuneCmd.Flags().Int("layers", 0, "Number of layers (block count)")
+	estimateFinetuneCmd.Flags().Int("hidden-size", 0, "Hidden size (embedding length)")
+	estimateFinetuneCmd.Flags().Int("ff-length", 0, "Feed forward length")
+	estimateFinetuneCmd.Flags().Int("heads", 0, "Attention heads")
+	estimateFinetuneCmd.Flags().Int("kv-heads", 0, "Key/value heads")
+}
@@ -17,12 +17,18 @@ var listModels = &cobra.Command{
 	Long: `List models using the Ollama api
 	
 If no model-name is espified all models will be retieved and listed. 
-You can pass the model-name as an argument or using the --model-name flag`,
+You can pass the model-name as an argument or using the --model-name flag
+
+Use --hosts to list the models of several servers at once, by name from the
+servers in the config or by address, or --hosts all for every configured server.
+A summary shows the models found on several hosts or missing from some.`,
 	// Args: cobra.ArbitraryArgs,
 	Run: func(cmd *cobra.Command, args []string) {
 		var (
 			model_name string
 			as_table   bool
+			as_chart   bool
+			hosts      []string
 			err        error
 		)
 
@@ -38,14 +44,41 @@ You can pass the model-name as an argument or using the --model-name flag`,
 			return
 		}
 
+		as_chart, err = cmd.Flags().GetBool("chart")
+		if err != nil {
+			fmt.Printf("getting chart flag: %+v", err)
+			return
+		}
+
+		hosts, err = cmd.Flags().GetStringSlice("hosts")
+		if err != nil {
+			fmt.Printf("getting hosts flag: %+v", err)
+			return
+		}
+
 		if len(args) > 0 {
 			model_name = args[0]
 		}
 
-		models.List(s, model_name, as_table)
+		if len(hosts) > 0 {
+			clients, err := newHosts(hosts)
+			if err != nil {
+				fmt.Printf("%+v\n", err)
+				return
+			}
+			defer closeHosts(clients)
+
+			models.ListHosts(cmd.Context(), clients, s, model_name, as_table, as_chart)
+			return
+		}
+
+		backend := newBackend()
+		defer backend.Close()
+
+		models.List(cmd.Context(), backend, s, model_name, as_table, as_chart)
 
 		// if as_table {
-		// 	models.ListTable(s, model_name)
+		// 	models.ListTable(cmd.Context(), client, model_name)
 		// } else {
 
 		// }
@@ -66,4 +99,6 @@ func init() {
 	// listModels.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 	listModels.Flags().StringP("model-name", "m", "", "Model to list")
 	listModels.Flags().BoolP("table", "t", false, "Print as table")
+	listModels.Flags().Bool("chart", false, "Chart GPU and system RAM against context length")
+	listModels.Flags().StringSlice("hosts", nil, "Servers to query, by name or address, or all")
 }
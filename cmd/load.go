@@ -0,0 +1,91 @@
+/*
+Copyright © 2025 Pato Diaz pato@patodiaz.io
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/padiazg/ollama-tools/internals/models"
+	"github.com/spf13/cobra"
+)
+
+// loadCmd represents the load command
+var loadCmd = &cobra.Command{
+	Use:   "load <model-name>",
+	Short: "Loads a model in memory and keeps it loaded",
+	Long: `Loads a model in memory and keeps it loaded
+
+Sends an empty generate request with the --keep-alive duration, and the --num-ctx
+context window if set, then waits until the model shows in /api/ps to report the VRAM
+it actually uses next to the GPU RAM we estimate for it.
+
+--keep-alive takes seconds or a duration like 10m or 2h, negative values like -1m keep
+the model loaded until the server stops.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var (
+			keep_alive string
+			num_ctx    int
+			err        error
+		)
+
+		if keep_alive, err = cmd.Flags().GetString("keep-alive"); err != nil {
+			fmt.Printf("getting keep-alive flag: %+v", err)
+			return
+		}
+
+		if num_ctx, err = cmd.Flags().GetInt("num-ctx"); err != nil {
+			fmt.Printf("getting num-ctx flag: %+v", err)
+			return
+		}
+
+		wait, err := cmd.Flags().GetDuration("wait")
+		if err != nil {
+			fmt.Printf("getting wait flag: %+v", err)
+			return
+		}
+
+		keep, err := models.ParseKeepAlive(keep_alive)
+		if err != nil {
+			fmt.Printf("%+v\n", err)
+			return
+		}
+
+		client, err := newClient()
+		if err != nil {
+			fmt.Printf("%+v\n", err)
+			return
+		}
+		defer client.Close()
+
+		result, err := models.Load(cmd.Context(), client, args[0], keep, num_ctx, wait)
+		if err != nil {
+			fmt.Printf("%+v\n", err)
+			return
+		}
+
+		models.PrintLoad(result)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(loadCmd)
+
+	loadCmd.Flags().StringP("keep-alive", "k", models.DefaultKeepAlive, "How long to keep the model loaded")
+	loadCmd.Flags().IntP("num-ctx", "c", 0, "Context window to load the model with, the model default if not set")
+	loadCmd.Flags().Duration("wait", models.DefaultLoadWait, "How long to wait for /api/ps to list the model")
+}
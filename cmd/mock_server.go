@@ -0,0 +1,88 @@
+/*
+Copyright © 2025 Pato Diaz pato@patodiaz.io
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/padiazg/ollama-tools/models/ollama/ollamatest"
+	"github.com/spf13/cobra"
+)
+
+// mockServerCmd represents the mock-server command
+var mockServerCmd = &cobra.Command{
+	Use:   "mock-server",
+	Short: "Runs a fake Ollama server",
+	Long: `Runs a fake Ollama server
+
+It answers /api/tags, /api/show, /api/ps, /api/generate, /api/chat, /api/embed,
+/api/pull, /api/delete and /api/copy from a YAML fixture of models, with canned metadata,
+streamed replies, latencies and injected errors, so tools can be tested without a
+real Ollama. Without --fixture a small built-in one is used. Stop it with Ctrl-C.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		var (
+			fixture_file string
+			addr         string
+			fixture      *ollamatest.Fixture
+			err          error
+		)
+
+		fixture_file, err = cmd.Flags().GetString("fixture")
+		if err != nil {
+			fmt.Printf("getting fixture flag: %+v", err)
+			return
+		}
+
+		addr, err = cmd.Flags().GetString("addr")
+		if err != nil {
+			fmt.Printf("getting addr flag: %+v", err)
+			return
+		}
+
+		if fixture_file == "" {
+			fixture = ollamatest.DefaultFixture()
+		} else if fixture, err = ollamatest.LoadFixture(fixture_file); err != nil {
+			fmt.Printf("loading fixture: %+v\n", err)
+			return
+		}
+
+		server := &http.Server{Addr: addr, Handler: ollamatest.New(fixture)}
+		go func() {
+			<-cmd.Context().Done()
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			_ = server.Shutdown(ctx)
+		}()
+
+		fmt.Printf("Mock Ollama server with %d models listening on http://%s\n", len(fixture.Models), addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Printf("serving: %+v\n", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mockServerCmd)
+
+	mockServerCmd.Flags().String("fixture", "", "YAML file with the models and errors to serve")
+	mockServerCmd.Flags().String("addr", "127.0.0.1:11434", "Address to listen on")
+}
@@ -0,0 +1,80 @@
+/*
+Copyright © 2025 Pato Diaz pato@patodiaz.io
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/padiazg/ollama-tools/internals/models"
+	"github.com/spf13/cobra"
+)
+
+// psCmd represents the ps command
+var psCmd = &cobra.Command{
+	Use:   "ps",
+	Short: "List the models loaded in memory using the Ollama api",
+	Long: `List the models loaded in memory using the Ollama api
+
+Shows the size, the VRAM used, the CPU/GPU split, the context length and when each
+model will be unloaded, next to the GPU RAM we estimate for it and the difference
+from the actual size.
+
+Use --hosts to list the models loaded on several servers at once, by name from the
+servers in the config or by address, or --hosts all for every configured server.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		as_table, err := cmd.Flags().GetBool("table")
+		if err != nil {
+			fmt.Printf("getting table flag: %+v", err)
+			return
+		}
+
+		hosts, err := cmd.Flags().GetStringSlice("hosts")
+		if err != nil {
+			fmt.Printf("getting hosts flag: %+v", err)
+			return
+		}
+
+		if len(hosts) > 0 {
+			clients, err := newHosts(hosts)
+			if err != nil {
+				fmt.Printf("%+v\n", err)
+				return
+			}
+			defer closeHosts(clients)
+
+			models.PsHosts(cmd.Context(), clients, as_table)
+			return
+		}
+
+		client, err := newClient()
+		if err != nil {
+			fmt.Printf("%+v\n", err)
+			return
+		}
+		defer client.Close()
+
+		models.Ps(cmd.Context(), client, as_table)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(psCmd)
+
+	psCmd.Flags().BoolP("table", "t", false, "Print as table")
+	psCmd.Flags().StringSlice("hosts", nil, "Servers to query, by name or address, or all")
+}
@@ -0,0 +1,72 @@
+/*
+Copyright © 2025 Pato Diaz pato@patodiaz.io
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/padiazg/ollama-tools/internals/models"
+	"github.com/spf13/cobra"
+)
+
+// pullCmd represents the pull command
+var pullCmd = &cobra.Command{
+	Use:   "pull <model-name>...",
+	Short: "Pulls one or more models using the Ollama api",
+	Long: `Pulls one or more models using the Ollama api
+
+Before pulling, the memory each model needs is estimated and checked against the
+configured hardware profile (hardware.vram, hardware.ram), models that are not
+installed are sized from their tag. Several models are pulled concurrently, showing
+the progress of every layer, and a summary is printed at the end.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var (
+			concurrency int
+			insecure    bool
+			err         error
+		)
+
+		concurrency, err = cmd.Flags().GetInt("concurrency")
+		if err != nil {
+			fmt.Printf("getting concurrency flag: %+v", err)
+			return
+		}
+
+		insecure, err = cmd.Flags().GetBool("insecure")
+		if err != nil {
+			fmt.Printf("getting insecure flag: %+v", err)
+			return
+		}
+
+		client, err := newClient()
+		if err != nil {
+			fmt.Printf("%+v\n", err)
+			return
+		}
+		defer client.Close()
+
+		models.Pull(cmd.Context(), client, s.Hardware, args, concurrency, insecure)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(pullCmd)
+
+	pullCmd.Flags().IntP("concurrency", "c", models.DefaultPullConcurrency, "Models to pull at the same time")
+	pullCmd.Flags().Bool("insecure", false, "Allow insecure connections to the registry")
+}
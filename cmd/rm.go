@@ -0,0 +1,102 @@
+/*
+Copyright © 2025 Pato Diaz pato@patodiaz.io
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/padiazg/ollama-tools/internals/models"
+	"github.com/spf13/cobra"
+)
+
+// rmCmd represents the rm command
+var rmCmd = &cobra.Command{
+	Use:   "rm [model-name|pattern]...",
+	Short: "Removes models by name, glob pattern, age or size",
+	Long: `Removes models by name, glob pattern, age or size
+
+Names without a tag mean :latest, globs like 'qwen2.5*:*-q8_0' are matched against
+the model names, a * also matches the / of namespaced models like 'hf.co/*'. --older-than and --larger-than are checked against the modification
+date and size of every model, and must all match along with one of the patterns.
+
+The plan is printed along with the disk space that will actually be reclaimed,
+blobs still used by other models don't count. Asks for confirmation unless --yes.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var (
+			f           = models.RemoveFilter{Patterns: args}
+			older_than  string
+			larger_than string
+			dry_run     bool
+			yes         bool
+			err         error
+		)
+
+		if older_than, err = cmd.Flags().GetString("older-than"); err != nil {
+			fmt.Printf("getting older-than flag: %+v", err)
+			return
+		}
+
+		if f.OlderThan, err = models.ParseAge(older_than); err != nil {
+			fmt.Printf("parsing older-than: %+v\n", err)
+			return
+		}
+
+		if larger_than, err = cmd.Flags().GetString("larger-than"); err != nil {
+			fmt.Printf("getting larger-than flag: %+v", err)
+			return
+		}
+
+		if f.LargerThan, err = models.ParseSize(larger_than); err != nil {
+			fmt.Printf("parsing larger-than: %+v\n", err)
+			return
+		}
+
+		if dry_run, err = cmd.Flags().GetBool("dry-run"); err != nil {
+			fmt.Printf("getting dry-run flag: %+v", err)
+			return
+		}
+
+		if yes, err = cmd.Flags().GetBool("yes"); err != nil {
+			fmt.Printf("getting yes flag: %+v", err)
+			return
+		}
+
+		if f.IsEmpty() {
+			_ = cmd.Usage()
+			return
+		}
+
+		client, err := newClient()
+		if err != nil {
+			fmt.Printf("%+v\n", err)
+			return
+		}
+		defer client.Close()
+
+		models.Remove(cmd.Context(), client, f, dry_run, yes, os.Stdin)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rmCmd)
+
+	rmCmd.Flags().String("older-than", "", "Only models not modified in this long (30d, 2w, 12h)")
+	rmCmd.Flags().String("larger-than", "", "Only models larger than this size (20GB, 500MB)")
+	rmCmd.Flags().BoolP("dry-run", "n", false, "Print the plan without removing anything")
+	rmCmd.Flags().BoolP("yes", "y", false, "Don't ask for confirmation")
+}
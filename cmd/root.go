@@ -17,20 +17,27 @@ along with this program. If not, see <http://www.gnu.org/licenses/>.
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
 	"reflect"
 	"strings"
 
+	"github.com/padiazg/ollama-tools/internals/cache"
+	"github.com/padiazg/ollama-tools/internals/capture"
+	"github.com/padiazg/ollama-tools/internals/models"
+	"github.com/padiazg/ollama-tools/models/ollama"
 	"github.com/padiazg/ollama-tools/models/settings"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 var (
-	cfgFile string
-	s       = &settings.Settings{}
+	cfgFile  string
+	s        = &settings.Settings{}
+	recorder *capture.Recorder
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -53,8 +60,16 @@ to quickly create a Cobra application.`,
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
-	err := rootCmd.Execute()
+	// cancel in-flight requests on Ctrl-C
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	err := rootCmd.ExecuteContext(ctx)
+	if recorder != nil {
+		recorder.Close()
+	}
 	if err != nil {
+		stop()
 		os.Exit(1)
 	}
 }
@@ -67,6 +82,42 @@ func init() {
 	// will be global for your application.
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.ollama-tools.yaml)")
+	rootCmd.PersistentFlags().Duration("timeout", 0, "time limit for each api request (default 1m)")
+	rootCmd.PersistentFlags().Int("retries", 0, "retries for idempotent api requests (default 3)")
+	rootCmd.PersistentFlags().Duration("max-elapsed", 0, "time limit for an api request and all its retries (default 5m)")
+	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "report retries and other details to stderr")
+	rootCmd.PersistentFlags().String("token-file", "", "file with a bearer token for a proxy in front of ollama")
+	rootCmd.PersistentFlags().String("username", "", "username for basic auth with a proxy in front of ollama")
+	rootCmd.PersistentFlags().String("password-file", "", "file with the password for basic auth")
+	rootCmd.PersistentFlags().String("backend", "", "api of the server, ollama or openai for OpenAI compatible servers (default ollama)")
+	rootCmd.PersistentFlags().Bool("no-cache", false, "ask the server for every model instead of using the cache")
+	rootCmd.PersistentFlags().String("proxy", "", "http proxy to reach ollama, instead of HTTP_PROXY")
+	rootCmd.PersistentFlags().String("tls-ca", "", "PEM bundle of the CA that signed the server certificate")
+	rootCmd.PersistentFlags().String("tls-cert", "", "client certificate for servers using mutual TLS")
+	rootCmd.PersistentFlags().String("tls-key", "", "key of the client certificate")
+	rootCmd.PersistentFlags().String("tls-server-name", "", "name to verify the server certificate against")
+	rootCmd.PersistentFlags().Bool("tls-insecure", false, "skip the server certificate verification")
+	rootCmd.PersistentFlags().StringArrayP("header", "H", nil, "extra header for every request, as 'Name: value'")
+	rootCmd.PersistentFlags().String("record", "", "save the api requests and responses to a file, credentials redacted")
+	rootCmd.PersistentFlags().String("replay", "", "answer the api requests from a file saved with --record, without a server")
+
+	bindFlags(map[string]string{
+		"requests.timeout":    "timeout",
+		"requests.retries":    "retries",
+		"requests.maxelapsed": "max-elapsed",
+		"verbose":             "verbose",
+		"auth.tokenfile":      "token-file",
+		"auth.username":       "username",
+		"auth.passwordfile":   "password-file",
+		"backend":             "backend",
+		"proxy":               "proxy",
+		"cache.disabled":      "no-cache",
+		"tls.cafile":          "tls-ca",
+		"tls.certfile":        "tls-cert",
+		"tls.keyfile":         "tls-key",
+		"tls.servername":      "tls-server-name",
+		"tls.insecure":        "tls-insecure",
+	})
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
@@ -106,11 +157,206 @@ func initConfig() {
 		log.Fatalf("unable to decode into struct, %v", err)
 	}
 
+	if err := s.ResolveOllamaUrl(viper.InConfig("ollamaurl"), viper.ConfigFileUsed()); err != nil {
+		log.Fatalf("config: ollama url: %v", err)
+	}
+
+	if err := s.CheckBackend(); err != nil {
+		log.Fatalf("config: %v", err)
+	}
+
+	if s.Verbose {
+		fmt.Fprintf(os.Stderr, "Using ollama at %s (from %s)\n", s.OllamaUrl, s.OllamaUrlSource())
+	}
+
+	headers, _ := rootCmd.PersistentFlags().GetStringArray("header")
+	for _, header := range headers {
+		name, value, err := settings.ParseHeader(header)
+		if err != nil {
+			log.Fatalf("config: %v", err)
+		}
+
+		if s.Auth.Headers == nil {
+			s.Auth.Headers = map[string]string{}
+		}
+		s.Auth.Headers[name] = value
+	}
+
+	if err := s.Auth.ReadSecrets(); err != nil {
+		log.Fatalf("config: %v", err)
+	}
+
+	if s.Cache.Dir == "" {
+		dir, err := cache.DefaultDir()
+		if err != nil {
+			log.Fatalf("config: cache: %v", err)
+		}
+		s.Cache.Dir = dir
+	}
+
+	// built once, so every command shares the same connections
+	if err := s.BuildTransport(); err != nil {
+		log.Fatalf("config: transport: %v", err)
+	}
+
+	if err := setupCapture(); err != nil {
+		log.Fatalf("config: %v", err)
+	}
+
 	// s.Show()
 }
 
+// setupCapture wraps the transport to record or replay the api calls
+func setupCapture() error {
+	record, _ := rootCmd.PersistentFlags().GetString("record")
+	replay, _ := rootCmd.PersistentFlags().GetString("replay")
+
+	// the cache would keep the models from being recorded, or fill it
+	// with replayed ones
+	if record != "" || replay != "" {
+		s.Cache.Disabled = true
+	}
+
+	switch {
+	case record != "" && replay != "":
+		return fmt.Errorf("use either --record or --replay")
+	case replay != "":
+		replayer, err := capture.NewReplayer(replay)
+		if err != nil {
+			return err
+		}
+		s.Transport = replayer
+	case record != "":
+		redact := make([]string, 0, len(s.Auth.Headers))
+		for name := range s.Auth.Headers {
+			redact = append(redact, name)
+		}
+		for _, server := range s.Servers {
+			if server.Auth != nil {
+				for name := range server.Auth.Headers {
+					redact = append(redact, name)
+				}
+			}
+		}
+
+		var err error
+		if recorder, err = capture.NewRecorder(record, s.Transport, redact); err != nil {
+			return err
+		}
+		s.Transport = recorder
+	}
+
+	return nil
+}
+
+// newClient returns an Ollama api client built from the loaded settings, the
+// commands using it need the Ollama api so other backends are refused
+func newClient() (*ollama.Client, error) {
+	if s.Backend != "" && s.Backend != settings.BackendOllama {
+		return nil, fmt.Errorf("this command needs the Ollama api, the %s backend only supports list-models and estimate", s.Backend)
+	}
+
+	return newClientFor(s), nil
+}
+
+// newClientFor returns an Ollama api client built from cfg
+func newClientFor(cfg *settings.Settings) *ollama.Client {
+	return ollama.NewClient(cfg.ApiUrl(), clientOptions(cfg)...)
+}
+
+// newBackend returns the backend to list and describe models with, the
+// commands that only work with the Ollama api use newClient
+func newBackend() ollama.Backend {
+	return newBackendFor(s)
+}
+
+func newBackendFor(cfg *settings.Settings) ollama.Backend {
+	var backend ollama.Backend
+	switch cfg.Backend {
+	case settings.BackendOpenAI:
+		backend = ollama.NewOpenAIBackend(cfg.ApiUrl(), clientOptions(cfg)...)
+	default:
+		backend = newClientFor(cfg)
+	}
+
+	if cfg.Cache.Disabled {
+		return backend
+	}
+
+	return cache.NewBackend(backend, newCache(cfg))
+}
+
+// newCache returns the `/api/show` cache in the configured directory
+func newCache(cfg *settings.Settings) *cache.Cache {
+	return cache.New(cfg.Cache.Dir)
+}
+
+// clientOptions returns the transport, limits and auth from cfg
+func clientOptions(cfg *settings.Settings) []ollama.ClientOption {
+	opts := []ollama.ClientOption{
+		ollama.WithTransport(cfg.Transport),
+		ollama.WithTimeout(cfg.Requests.Timeout),
+		ollama.WithRetry(ollama.RetryPolicy{
+			Retries:    cfg.Requests.Retries,
+			Wait:       cfg.Requests.RetryWait,
+			MaxWait:    cfg.Requests.RetryMaxWait,
+			MaxElapsed: cfg.Requests.MaxElapsed,
+		}),
+	}
+
+	if cfg.Verbose {
+		opts = append(opts, ollama.WithLogger(os.Stderr))
+	}
+
+	if len(cfg.Auth.Headers) > 0 {
+		opts = append(opts, ollama.WithHeaders(cfg.Auth.Headers))
+	}
+
+	switch {
+	case cfg.Auth.Token != "":
+		opts = append(opts, ollama.WithBearerToken(cfg.Auth.Token))
+	case cfg.Auth.Username != "":
+		opts = append(opts, ollama.WithBasicAuth(cfg.Auth.Username, cfg.Auth.Password))
+	}
+
+	return opts
+}
+
+// newHosts returns a backend for each of the servers named by `--hosts`,
+// they must be closed
+func newHosts(names []string) ([]*models.Host, error) {
+	servers, err := s.SelectServers(names)
+	if err != nil {
+		return nil, err
+	}
+
+	hosts := make([]*models.Host, 0, len(servers))
+	for _, server := range servers {
+		cfg, err := s.ForServer(server)
+		if err != nil {
+			closeHosts(hosts)
+			return nil, err
+		}
+		hosts = append(hosts, &models.Host{Name: server.Name, Backend: newBackendFor(cfg)})
+	}
+
+	return hosts, nil
+}
+
+func closeHosts(hosts []*models.Host) {
+	for _, host := range hosts {
+		host.Backend.Close()
+	}
+}
+
 func setDefaults() {
 	viper.SetDefault("ollamaurl", "http://localhost:11434")
+	viper.SetDefault("backend", settings.BackendOllama)
+	viper.SetDefault("requests.timeout", "1m")
+	viper.SetDefault("requests.retries", 3)
+	viper.SetDefault("requests.retrywait", "500ms")
+	viper.SetDefault("requests.retrymaxwait", "10s")
+	viper.SetDefault("requests.maxelapsed", "5m")
 	// viper.SetDefault("webserver.adminport", 3001)
 	// viper.SetDefault("webserver.tls_enabled", false)
 	// viper.SetDefault("webserver.static.path", "./static")
@@ -122,6 +368,16 @@ func setDefaults() {
 	// viper.SetDefault("internals.adminenabled", true)
 }
 
+// bindFlags binds config keys to root persistent flags, a flag only
+// overrides the config when it's set
+func bindFlags(keys map[string]string) {
+	for key, name := range keys {
+		if err := viper.BindPFlag(key, rootCmd.PersistentFlags().Lookup(name)); err != nil {
+			log.Fatalf("config: unable to bind flag %s: %s", name, err.Error())
+		}
+	}
+}
+
 // bindEnvs creates the environment variable bindings for the given struct, also aliases for proper
 // binding of environment variables and values from .env files and other structured config files.
 func bindEnvs(i interface{}, parts ...string) {
@@ -161,6 +161,29 @@ func Test_bindEnvs(t *testing.T) {
 			},
 			want: []string{
 				"ollamaurl",
+				"backend",
+				"proxy",
+				"hardware.vram",
+				"hardware.ram",
+				"requests.timeout",
+				"requests.retries",
+				"requests.retrywait",
+				"requests.retrymaxwait",
+				"requests.maxelapsed",
+				"verbose",
+				"auth.token",
+				"auth.tokenfile",
+				"auth.username",
+				"auth.password",
+				"auth.passwordfile",
+				"tls.cafile",
+				"tls.certfile",
+				"tls.keyfile",
+				"tls.servername",
+				"tls.insecure",
+				"servers",
+				"cache.dir",
+				"cache.disabled",
 				// "webserver.port",
 				// "webserver.tlsenabled",
 				// "webserver.tlsminversion",
@@ -197,3 +220,30 @@ func Test_bindEnvs(t *testing.T) {
 		})
 	}
 }
+
+func Test_newClient(t *testing.T) {
+	saved := s
+	defer func() { s = saved }()
+
+	tests := []struct {
+		backend string
+		wantErr bool
+	}{
+		{backend: ""},
+		{backend: settings.BackendOllama},
+		{backend: settings.BackendOpenAI, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.backend, func(t *testing.T) {
+			s = &settings.Settings{OllamaUrl: "http://ollama:11434", Backend: tt.backend}
+
+			client, err := newClient()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("newClient() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if client != nil {
+				client.Close()
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+/*
+Copyright © 2025 Pato Diaz pato@patodiaz.io
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/padiazg/ollama-tools/internals/models"
+	"github.com/padiazg/ollama-tools/internals/tools"
+	"github.com/spf13/cobra"
+)
+
+// simulateCmd represents the simulate command
+var simulateCmd = &cobra.Command{
+	Use:   "simulate <model-name> <transcript.json>",
+	Short: "Simulates the context and KV cache growth over a conversation",
+	Long: `Simulates the context and KV cache growth over a conversation
+
+The transcript is a JSON file with chat messages, either a plain array of
+{"role", "content"} objects or a chat request with a "messages" field. Token counts
+are approximated from the message length. Warns at the turn where the context
+window would overflow and Ollama would start truncating.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		var (
+			num_ctx         int
+			chars_per_token float64
+			err             error
+		)
+
+		num_ctx, err = cmd.Flags().GetInt("num-ctx")
+		if err != nil {
+			fmt.Printf("getting num-ctx: %+v", err)
+			return
+		}
+
+		chars_per_token, err = cmd.Flags().GetFloat64("chars-per-token")
+		if err != nil {
+			fmt.Printf("getting chars-per-token: %+v", err)
+			return
+		}
+
+		client, err := newClient()
+		if err != nil {
+			fmt.Printf("%+v\n", err)
+			return
+		}
+		defer client.Close()
+
+		models.Simulate(cmd.Context(), client, args[0], args[1], num_ctx, chars_per_token)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(simulateCmd)
+
+	simulateCmd.Flags().IntP("num-ctx", "c", tools.DefaultNumCtx, "Context window (num_ctx)")
+	simulateCmd.Flags().Float64("chars-per-token", tools.DefaultCharsPerToken, "Characters per token used to approximate token counts")
+}
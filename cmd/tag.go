@@ -0,0 +1,127 @@
+/*
+Copyright © 2025 Pato Diaz pato@patodiaz.io
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/padiazg/ollama-tools/internals/models"
+	"github.com/spf13/cobra"
+)
+
+// tagCmd represents the tag command
+var tagCmd = &cobra.Command{
+	Use:     "tag",
+	Aliases: []string{"cp"},
+	Short:   "Creates, moves and lists model aliases",
+	Long: `Creates, moves and lists model aliases
+
+Aliases are extra names for a model, like team/coder:current, that share its blobs
+so they take no extra disk space.`,
+}
+
+// tagCreateCmd represents the tag create command
+var tagCreateCmd = &cobra.Command{
+	Use:   "create <model-name> <alias>",
+	Short: "Creates an alias for a model",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		force, err := cmd.Flags().GetBool("force")
+		if err != nil {
+			fmt.Printf("getting force flag: %+v", err)
+			return
+		}
+
+		client, err := newClient()
+		if err != nil {
+			fmt.Printf("%+v\n", err)
+			return
+		}
+		defer client.Close()
+
+		if err := models.TagCreate(cmd.Context(), client, args[0], args[1], force); err != nil {
+			fmt.Printf("creating alias: %+v\n", err)
+			return
+		}
+		fmt.Printf("copied '%s' to '%s'\n", args[0], args[1])
+	},
+}
+
+// tagMoveCmd represents the tag move command
+var tagMoveCmd = &cobra.Command{
+	Use:   "move <alias> <new-alias>",
+	Short: "Renames an alias, copying it to the new name and removing the old one",
+	Long: `Renames an alias, copying it to the new name and removing the old one
+
+With --digest the alias is only moved when it points to that digest, a prefix of at
+least 6 characters is enough. An existing new-alias is only replaced with --force.`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		digest, err := cmd.Flags().GetString("digest")
+		if err != nil {
+			fmt.Printf("getting digest flag: %+v", err)
+			return
+		}
+
+		force, err := cmd.Flags().GetBool("force")
+		if err != nil {
+			fmt.Printf("getting force flag: %+v", err)
+			return
+		}
+
+		client, err := newClient()
+		if err != nil {
+			fmt.Printf("%+v\n", err)
+			return
+		}
+		defer client.Close()
+
+		if err := models.TagMove(cmd.Context(), client, args[0], args[1], digest, force); err != nil {
+			fmt.Printf("moving alias: %+v\n", err)
+			return
+		}
+		fmt.Printf("moved '%s' to '%s'\n", args[0], args[1])
+	},
+}
+
+// tagListCmd represents the tag list command
+var tagListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the models grouped by digest, showing which names are aliases",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		client, err := newClient()
+		if err != nil {
+			fmt.Printf("%+v\n", err)
+			return
+		}
+		defer client.Close()
+
+		models.TagList(cmd.Context(), client)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(tagCmd)
+	tagCmd.AddCommand(tagCreateCmd)
+	tagCmd.AddCommand(tagMoveCmd)
+	tagCmd.AddCommand(tagListCmd)
+
+	tagCreateCmd.Flags().BoolP("force", "f", false, "Replace the alias if it already exists")
+	tagMoveCmd.Flags().String("digest", "", "Only move the alias if it points to this digest")
+	tagMoveCmd.Flags().BoolP("force", "f", false, "Replace the new alias if it already exists")
+}
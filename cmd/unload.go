@@ -0,0 +1,75 @@
+/*
+Copyright © 2025 Pato Diaz pato@patodiaz.io
+
+This program is free software: you can redistribute it and/or modify
+it under the terms of the GNU General Public License as published by
+the Free Software Foundation, either version 3 of the License, or
+(at your option) any later version.
+
+This program is distributed in the hope that it will be useful,
+but WITHOUT ANY WARRANTY; without even the implied warranty of
+MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+GNU General Public License for more details.
+
+You should have received a copy of the GNU General Public License
+along with this program. If not, see <http://www.gnu.org/licenses/>.
+*/
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/padiazg/ollama-tools/internals/models"
+	"github.com/spf13/cobra"
+)
+
+// unloadCmd represents the unload command
+var unloadCmd = &cobra.Command{
+	Use:   "unload <model-name>... | --all",
+	Short: "Unloads models from memory",
+	Long: `Unloads models from memory
+
+Sends an empty generate request with keep_alive 0 for each model, or for every loaded
+model with --all, then waits until /api/ps no longer lists them to report the VRAM freed
+next to the GPU RAM we estimated for each one.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		all, err := cmd.Flags().GetBool("all")
+		if err != nil {
+			fmt.Printf("getting all flag: %+v", err)
+			return
+		}
+
+		wait, err := cmd.Flags().GetDuration("wait")
+		if err != nil {
+			fmt.Printf("getting wait flag: %+v", err)
+			return
+		}
+
+		if all == (len(args) > 0) {
+			fmt.Println("give the models to unload or --all")
+			return
+		}
+
+		client, err := newClient()
+		if err != nil {
+			fmt.Printf("%+v\n", err)
+			return
+		}
+		defer client.Close()
+
+		unloaded, err := models.Unload(cmd.Context(), client, args, all, wait)
+		if err != nil {
+			fmt.Printf("%+v\n", err)
+			return
+		}
+
+		models.PrintUnload(unloaded)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(unloadCmd)
+
+	unloadCmd.Flags().BoolP("all", "a", false, "Unload every loaded model")
+	unloadCmd.Flags().Duration("wait", models.DefaultLoadWait, "How long to wait for /api/ps to confirm")
+}
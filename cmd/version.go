@@ -4,6 +4,8 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"fmt"
+
 	"github.com/padiazg/ollama-tools/models/version"
 	"github.com/spf13/cobra"
 )
@@ -11,15 +13,28 @@ import (
 // versionCmd represents the version command
 var versionCmd = &cobra.Command{
 	Use:   "version",
-	Short: "A brief description of your command",
-	Long: `A longer description that spans multiple lines and likely contains examples
-and usage of using your command. For example:
+	Short: "Prints the version of ollama-tools and of the Ollama server",
+	Long: `Prints the version of ollama-tools and of the Ollama server it's connected to.
 
-Cobra is a CLI library for Go that empowers applications.
-This application is a tool to generate the needed files
-to quickly create a Cobra application.`,
+Some commands depend on api endpoints or fields that only newer servers have, those
+either fail with a "requires Ollama >= x.y" message or work with what the server has.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		version.Splash()
+
+		client, err := newClient()
+		if err != nil {
+			fmt.Printf("%+v\n", err)
+			return
+		}
+		defer client.Close()
+
+		server, err := client.Version(cmd.Context())
+		if err != nil {
+			fmt.Printf("Ollama server at %s (from %s): %+v\n", s.OllamaUrl, s.OllamaUrlSource(), err)
+			return
+		}
+
+		fmt.Printf("Ollama server at %s (from %s): %s\n", s.OllamaUrl, s.OllamaUrlSource(), server)
 	},
 }
 
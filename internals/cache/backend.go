@@ -0,0 +1,103 @@
+package cache
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/padiazg/ollama-tools/models/ollama"
+)
+
+// Backend looks models up in the cache before asking the server. The
+// digests come from Tags, which is called once if Show comes first.
+// Models without a digest, like the ones of OpenAI compatible servers,
+// are always fetched
+type Backend struct {
+	ollama.Backend
+	cache *Cache
+
+	mu      sync.Mutex
+	tagged  bool
+	digests map[string]string
+}
+
+func NewBackend(backend ollama.Backend, cache *Cache) *Backend {
+	return &Backend{Backend: backend, cache: cache, digests: map[string]string{}}
+}
+
+// Unwrap returns the backend the cache is in front of
+func (b *Backend) Unwrap() ollama.Backend {
+	return b.Backend
+}
+
+// Tags lists the models and takes note of their digests
+func (b *Backend) Tags(ctx context.Context) (*ollama.Tags, error) {
+	tags, err := b.Backend.Tags(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tagged = true
+	clear(b.digests)
+	for _, tag := range tags.Models {
+		b.digests[tag.Name] = tag.Digest
+		b.digests[tag.Model] = tag.Digest
+	}
+
+	return tags, nil
+}
+
+// Show returns the cached model for the current digest, or fetches it and
+// caches it. A cache that can't be written is only a slower run
+func (b *Backend) Show(ctx context.Context, model_name string) (*ollama.Model, error) {
+	digest := b.digest(ctx, model_name)
+	if digest == "" {
+		return b.Backend.Show(ctx, model_name)
+	}
+
+	if model, ok := b.cache.Get(digest); ok {
+		return model, nil
+	}
+
+	model, err := b.Backend.Show(ctx, model_name)
+	if err != nil {
+		return nil, err
+	}
+
+	_ = b.cache.Put(digest, model)
+
+	return model, nil
+}
+
+func (b *Backend) digest(ctx context.Context, model_name string) string {
+	b.mu.Lock()
+	tagged := b.tagged
+	b.mu.Unlock()
+
+	// a failed Tags isn't tried again, the models are just fetched
+	if !tagged {
+		if _, err := b.Tags(ctx); err != nil {
+			b.mu.Lock()
+			b.tagged = true
+			b.mu.Unlock()
+			return ""
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if digest, ok := b.digests[model_name]; ok {
+		return digest
+	}
+
+	// like the ollama cli, a name without a tag is `:latest`
+	if !strings.Contains(model_name, ":") {
+		return b.digests[model_name+":latest"]
+	}
+
+	return ""
+}
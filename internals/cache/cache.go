@@ -0,0 +1,139 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/padiazg/ollama-tools/models/ollama"
+)
+
+// entryVersion is bumped when ollama.Model changes, so older entries are
+// fetched again
+const entryVersion = 1
+
+// Cache keeps the `/api/show` results on disk, keyed by the model digest.
+// A model is only the same while its digest is, so entries never expire,
+// a pulled or rebuilt model just gets a new one
+type Cache struct {
+	dir string
+}
+
+type entry struct {
+	Version int           `json:"version"`
+	Digest  string        `json:"digest"`
+	Model   *ollama.Model `json:"model"`
+}
+
+// DefaultDir returns the ollama-tools folder in the user cache directory
+func DefaultDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("getting the user cache directory: %+v", err)
+	}
+
+	return filepath.Join(dir, "ollama-tools"), nil
+}
+
+// New returns a cache in dir, it's created on the first write
+func New(dir string) *Cache {
+	return &Cache{dir: dir}
+}
+
+func (c *Cache) Dir() string {
+	return c.dir
+}
+
+// Get returns the model cached for digest
+func (c *Cache) Get(digest string) (*ollama.Model, bool) {
+	file, ok := c.file(digest)
+	if !ok {
+		return nil, false
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, false
+	}
+
+	e := &entry{}
+	if err := json.Unmarshal(data, e); err != nil || e.Version != entryVersion || e.Digest != digest || e.Model == nil {
+		return nil, false
+	}
+
+	return e.Model, true
+}
+
+// Put stores the model for digest, the file is renamed into place so
+// concurrent runs never read half an entry
+func (c *Cache) Put(digest string, model *ollama.Model) error {
+	file, ok := c.file(digest)
+	if !ok {
+		return fmt.Errorf("invalid digest %q", digest)
+	}
+
+	data, err := json.Marshal(&entry{Version: entryVersion, Digest: digest, Model: model})
+	if err != nil {
+		return fmt.Errorf("encoding cache entry: %+v", err)
+	}
+
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return fmt.Errorf("creating cache directory: %+v", err)
+	}
+
+	tmp, err := os.CreateTemp(c.dir, ".entry-*")
+	if err != nil {
+		return fmt.Errorf("writing cache entry: %+v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing cache entry: %+v", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing cache entry: %+v", err)
+	}
+
+	if err := os.Rename(tmp.Name(), file); err != nil {
+		return fmt.Errorf("writing cache entry: %+v", err)
+	}
+
+	return nil
+}
+
+// Clear removes every entry and returns how many there were
+func (c *Cache) Clear() (int, error) {
+	files, err := filepath.Glob(filepath.Join(c.dir, "*.json"))
+	if err != nil {
+		return 0, err
+	}
+
+	for _, file := range files {
+		if err := os.Remove(file); err != nil {
+			return 0, fmt.Errorf("removing cache entry: %+v", err)
+		}
+	}
+
+	return len(files), nil
+}
+
+// file returns the path of the entry for digest, only hex digests are
+// valid so they can't point outside the cache
+func (c *Cache) file(digest string) (string, bool) {
+	name := strings.TrimPrefix(digest, "sha256:")
+	if name == "" {
+		return "", false
+	}
+
+	for _, r := range name {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return "", false
+		}
+	}
+
+	return filepath.Join(c.dir, name+".json"), true
+}
@@ -0,0 +1,126 @@
+package cache
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/padiazg/ollama-tools/models/ollama"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	digestPhi4  = "ac896e5b8b34a1f4efa7b14d7520725140d5512484457fab45d2a4ea14c69dba"
+	digestPhi4b = "ff10e5b8b34a1f4efa7b14d7520725140d5512484457fab45d2a4ea14c69dba0"
+)
+
+func phi4Model() *ollama.Model {
+	return &ollama.Model{
+		Details: ollama.ModelDetails{Format: "gguf", Family: "phi3", Families: []string{"phi3"}, ParameterSize: "14.7B", QuantizationLevel: "Q4_K_M"},
+		ModelInfo: ollama.ModelInfo{
+			Type:              "model",
+			ParameterCount:    14659507200,
+			ContextLength:     16384,
+			EmbeddingLength:   5120,
+			BlockCount:        40,
+			FeedForwardLength: 17920,
+			HeadCount:         40,
+			HeadCountKV:       10,
+		},
+		Capabilities: []string{"completion"},
+	}
+}
+
+func TestCache(t *testing.T) {
+	c := New(filepath.Join(t.TempDir(), "ollama-tools"))
+
+	_, ok := c.Get(digestPhi4)
+	assert.False(t, ok)
+
+	if !assert.NoError(t, c.Put(digestPhi4, phi4Model())) {
+		return
+	}
+
+	got, ok := c.Get(digestPhi4)
+	if assert.True(t, ok) {
+		assert.Equal(t, phi4Model(), got)
+	}
+
+	got, ok = c.Get("sha256:" + digestPhi4)
+	assert.False(t, ok, "the digest is stored as given")
+	assert.Nil(t, got)
+
+	assert.Error(t, c.Put("../../etc/passwd", phi4Model()))
+
+	// entries of an older format are ignored
+	assert.NoError(t, os.WriteFile(filepath.Join(c.Dir(), digestPhi4b+".json"), []byte(`{"version":0,"digest":"`+digestPhi4b+`","model":{}}`), 0o644))
+	_, ok = c.Get(digestPhi4b)
+	assert.False(t, ok)
+
+	removed, err := c.Clear()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, removed)
+
+	_, ok = c.Get(digestPhi4)
+	assert.False(t, ok)
+}
+
+// countingBackend serves phi4 with the digest given, counting the calls
+type countingBackend struct {
+	digest string
+	tags   int
+	shows  int
+}
+
+func (b *countingBackend) Tags(ctx context.Context) (*ollama.Tags, error) {
+	b.tags++
+	return &ollama.Tags{Models: []ollama.TagModel{{Name: "phi4:latest", Model: "phi4:latest", Digest: b.digest}}}, nil
+}
+
+func (b *countingBackend) Show(ctx context.Context, model_name string) (*ollama.Model, error) {
+	b.shows++
+	return phi4Model(), nil
+}
+
+func (b *countingBackend) BaseUrl() string { return "http://ollama:11434" }
+
+func (b *countingBackend) Close() error { return nil }
+
+func TestBackend(t *testing.T) {
+	var (
+		ctx   = context.Background()
+		c     = New(t.TempDir())
+		inner = &countingBackend{digest: digestPhi4}
+	)
+
+	run := func(name string) {
+		b := NewBackend(inner, c)
+		got, err := b.Show(ctx, name)
+		if assert.NoError(t, err) {
+			assert.Equal(t, phi4Model(), got)
+		}
+	}
+
+	// Show without Tags looks the digest up first
+	run("phi4")
+	assert.Equal(t, 1, inner.tags)
+	assert.Equal(t, 1, inner.shows)
+
+	run("phi4:latest")
+	assert.Equal(t, 1, inner.shows, "served from the cache")
+
+	// a new digest is a new model
+	inner.digest = digestPhi4b
+	run("phi4:latest")
+	assert.Equal(t, 2, inner.shows)
+
+	// models without a digest aren't cached
+	inner.digest = ""
+	run("phi4:latest")
+	run("phi4:latest")
+	assert.Equal(t, 4, inner.shows)
+
+	b := NewBackend(inner, c)
+	assert.Same(t, inner, b.Unwrap())
+}
@@ -0,0 +1,77 @@
+package capture
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// Redacted replaces the value of the headers that carry credentials
+const Redacted = "REDACTED"
+
+// sensitiveHeaders are always redacted, the extra headers of the settings
+// are added to them as they can hold api keys
+var sensitiveHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+
+// Exchange is a request and the response the server gave, saved one per
+// line in a capture file
+type Exchange struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request only keeps the host, not the whole url, so a capture can be
+// replayed against any address
+type Request struct {
+	Method string      `json:"method"`
+	Host   string      `json:"host,omitempty"`
+	Path   string      `json:"path"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+}
+
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body"`
+}
+
+// redact returns a copy of header with the sensitive values replaced
+func redact(header http.Header, extra []string) http.Header {
+	if len(header) == 0 {
+		return nil
+	}
+
+	redacted := header.Clone()
+	for _, name := range append(sensitiveHeaders, extra...) {
+		if redacted.Get(name) != "" {
+			redacted.Set(name, Redacted)
+		}
+	}
+
+	return redacted
+}
+
+// requestPath returns the path with the query, if any
+func requestPath(r *http.Request) string {
+	if r.URL.RawQuery == "" {
+		return r.URL.Path
+	}
+	return r.URL.Path + "?" + r.URL.RawQuery
+}
+
+// sameBody compares json bodies by value, so the order of the fields and
+// the spacing don't matter
+func sameBody(a string, b string) bool {
+	if a == b {
+		return true
+	}
+
+	var va, vb any
+	if json.Unmarshal([]byte(a), &va) != nil || json.Unmarshal([]byte(b), &vb) != nil {
+		return strings.TrimSpace(a) == strings.TrimSpace(b)
+	}
+
+	return reflect.DeepEqual(va, vb)
+}
@@ -0,0 +1,173 @@
+package capture
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/padiazg/ollama-tools/models/ollama"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordReplay(t *testing.T) {
+	var (
+		ctx  = context.Background()
+		file = filepath.Join(t.TempDir(), "capture.jsonl")
+		ts   = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case ollama.ApiPathTags:
+				_, _ = w.Write([]byte(`{"models":[{"name":"phi4:latest","digest":"ac896e5b8b34"}]}`))
+			case ollama.ApiPathShow:
+				_, _ = w.Write([]byte(`{"details":{"family":"phi3","quantization_level":"Q4_K_M"},"model_info":{"general.parameter_count":14659507200,"phi3.context_length":16384}}`))
+			case ollama.ApiPathPull:
+				_, _ = w.Write([]byte("{\"status\":\"pulling manifest\"}\n{\"status\":\"success\"}\n"))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+	)
+	defer ts.Close()
+
+	recorder, err := NewRecorder(file, nil, []string{"X-Team-Key"})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	client := ollama.NewClient(ts.URL,
+		ollama.WithTransport(recorder),
+		ollama.WithBearerToken("secret-token"),
+		ollama.WithHeaders(map[string]string{"X-Team-Key": "secret-key", "X-Team": "ml"}),
+	)
+
+	_, err = client.Tags(ctx)
+	assert.NoError(t, err)
+	// a wrapped transport, like a unix socket host gets, writes to the same file
+	wrapped := ollama.NewClient(ts.URL, ollama.WithTransport(recorder.Wrap(&http.Transport{})))
+	_, err = wrapped.Show(ctx, "phi4:latest")
+	assert.NoError(t, err)
+	wrapped.Close()
+	statuses := []string{}
+	assert.NoError(t, client.Pull(ctx, &ollama.PullRequest{Model: "phi4:latest"}, func(p *ollama.ProgressResponse) error {
+		statuses = append(statuses, p.Status)
+		return nil
+	}))
+	client.Close()
+	assert.NoError(t, recorder.Close())
+
+	data, err := os.ReadFile(file)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 3, strings.Count(string(data), "\n"))
+	assert.NotContains(t, string(data), "secret")
+	assert.Contains(t, string(data), `"X-Team":["ml"]`)
+
+	// the same calls, with no server
+	ts.Close()
+
+	replayer, err := NewReplayer(file)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Same(t, replayer, replayer.Wrap(&http.Transport{}), "wrapped transports are replayed too")
+
+	client = ollama.NewClient("http://elsewhere:11434", ollama.WithTransport(replayer))
+	defer client.Close()
+
+	tags, err := client.Tags(ctx)
+	if assert.NoError(t, err) && assert.Len(t, tags.Models, 1) {
+		assert.Equal(t, "ac896e5b8b34", tags.Models[0].Digest)
+	}
+
+	model, err := client.Show(ctx, "phi4:latest")
+	if assert.NoError(t, err) {
+		assert.Equal(t, 16384, model.ModelInfo.ContextLength)
+	}
+
+	replayed := []string{}
+	assert.NoError(t, client.Pull(ctx, &ollama.PullRequest{Model: "phi4:latest"}, func(p *ollama.ProgressResponse) error {
+		replayed = append(replayed, p.Status)
+		return nil
+	}))
+	assert.Equal(t, statuses, replayed)
+
+	_, err = client.Show(ctx, "llama3.1:latest")
+	assert.ErrorContains(t, err, "no recorded response for POST /api/show")
+}
+
+func TestReplayer_find(t *testing.T) {
+	r := &Replayer{exchanges: []*Exchange{
+		{Request: Request{Method: http.MethodGet, Host: "gpu-1:11434", Path: "/api/ps"}, Response: Response{Body: "gpu-1 first"}},
+		{Request: Request{Method: http.MethodGet, Host: "gpu-2:11434", Path: "/api/ps"}, Response: Response{Body: "gpu-2"}},
+		{Request: Request{Method: http.MethodGet, Host: "gpu-1:11434", Path: "/api/ps"}, Response: Response{Body: "gpu-1 second"}},
+		{Request: Request{Method: http.MethodPost, Path: "/api/show", Body: `{"model":"phi4:latest"}`}, Response: Response{Body: "phi4"}},
+	}}
+	r.served = make([]bool, len(r.exchanges))
+
+	body := func(e *Exchange) string {
+		if e == nil {
+			return ""
+		}
+		return e.Response.Body
+	}
+
+	assert.Equal(t, "gpu-1 first", body(r.find(http.MethodGet, "gpu-1:11434", "/api/ps", "")))
+	assert.Equal(t, "gpu-2", body(r.find(http.MethodGet, "gpu-2:11434", "/api/ps", "")))
+	assert.Equal(t, "gpu-1 second", body(r.find(http.MethodGet, "gpu-1:11434", "/api/ps", "")))
+	// run out, the last one of the host is repeated
+	assert.Equal(t, "gpu-1 second", body(r.find(http.MethodGet, "gpu-1:11434", "/api/ps", "")))
+	assert.Equal(t, "gpu-2", body(r.find(http.MethodGet, "gpu-2:11434", "/api/ps", "")))
+
+	// json bodies are compared by value
+	assert.Equal(t, "phi4", body(r.find(http.MethodPost, "ollama:11434", "/api/show", `{ "model": "phi4:latest" }`)))
+	assert.Nil(t, r.find(http.MethodPost, "ollama:11434", "/api/show", `{"model":"llama3.1:latest"}`))
+}
+
+func TestRecordReplay_upload(t *testing.T) {
+	var (
+		ctx      = context.Background()
+		file     = filepath.Join(t.TempDir(), "capture.jsonl")
+		digest   = "sha256:" + strings.Repeat("ab", 32)
+		uploaded int
+		ts       = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			data, _ := io.ReadAll(r.Body)
+			uploaded = len(data)
+			w.WriteHeader(http.StatusCreated)
+		}))
+	)
+	defer ts.Close()
+
+	recorder, err := NewRecorder(file, nil, nil)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	client := ollama.NewClient(ts.URL, ollama.WithTransport(recorder))
+	assert.NoError(t, client.CreateBlob(ctx, digest, bytes.NewReader(make([]byte, 1<<20))))
+	client.Close()
+	assert.NoError(t, recorder.Close())
+	assert.Equal(t, 1<<20, uploaded)
+
+	data, err := os.ReadFile(file)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Contains(t, string(data), `"body":"[upload of 1048576 bytes]"`)
+	assert.Less(t, len(data), 4096, "the upload is not kept")
+
+	replayer, err := NewReplayer(file)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	client = ollama.NewClient("http://elsewhere:11434", ollama.WithTransport(replayer))
+	defer client.Close()
+	assert.NoError(t, client.CreateBlob(ctx, digest, bytes.NewReader(make([]byte, 1<<20))))
+}
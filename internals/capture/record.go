@@ -0,0 +1,184 @@
+package capture
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/padiazg/ollama-tools/models/ollama"
+)
+
+// Recorder is a transport that saves every request and its response to a
+// file, with the credentials redacted, for Replayer to serve them later
+type Recorder struct {
+	transport http.RoundTripper
+	redact    []string
+	out       *output
+}
+
+// output is the capture file, shared by the recorders Wrap returns
+type output struct {
+	mu   sync.Mutex
+	file *os.File
+	enc  *json.Encoder
+}
+
+// NewRecorder creates the capture file, redact are extra headers to hide
+func NewRecorder(path string, transport http.RoundTripper, redact []string) (*Recorder, error) {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("creating capture file: %+v", err)
+	}
+
+	return &Recorder{transport: transport, redact: redact, out: &output{file: file, enc: json.NewEncoder(file)}}, nil
+}
+
+// Wrap returns a recorder for another transport that writes to the same
+// file, for servers that need their own, like unix sockets
+func (r *Recorder) Wrap(transport http.RoundTripper) http.RoundTripper {
+	return &Recorder{transport: transport, redact: r.redact, out: r.out}
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var (
+		body   string
+		upload *countingBody
+		err    error
+	)
+
+	// uploads can be gigabytes, they go straight to the server and only
+	// their size is kept
+	if isUpload(req) {
+		upload = &countingBody{ReadCloser: req.Body}
+		req.Body = upload
+	} else if body, err = readBody(req); err != nil {
+		return nil, err
+	}
+
+	res, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Exchange{
+		Request: Request{
+			Method: req.Method,
+			Host:   req.URL.Host,
+			Path:   requestPath(req),
+			Header: redact(req.Header, r.redact),
+			Body:   body,
+		},
+		Response: Response{
+			StatusCode: res.StatusCode,
+			Header:     redact(res.Header, r.redact),
+		},
+	}
+
+	// streamed responses are saved once they're read, so the progress is
+	// still shown as it comes
+	res.Body = &recordingBody{ReadCloser: res.Body, done: func(data []byte) {
+		if upload != nil {
+			e.Request.Body = uploadBody(upload.n.Load())
+		}
+		e.Response.Body = string(data)
+		r.write(e)
+	}}
+
+	return res, nil
+}
+
+// Close closes the capture file
+func (r *Recorder) Close() error {
+	r.out.mu.Lock()
+	defer r.out.mu.Unlock()
+
+	return r.out.file.Close()
+}
+
+func (r *Recorder) write(e *Exchange) {
+	r.out.mu.Lock()
+	defer r.out.mu.Unlock()
+
+	if err := r.out.enc.Encode(e); err != nil {
+		fmt.Fprintf(os.Stderr, "writing capture: %+v\n", err)
+	}
+}
+
+// readBody reads the request body and puts it back for the transport
+func readBody(req *http.Request) (string, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return "", nil
+	}
+
+	data, err := io.ReadAll(req.Body)
+	if err != nil {
+		return "", fmt.Errorf("reading request body: %+v", err)
+	}
+	req.Body.Close()
+	req.Body = io.NopCloser(bytes.NewReader(data))
+
+	return string(data), nil
+}
+
+// isUpload tells if the request sends a file, like a blob for create,
+// rather than json
+func isUpload(req *http.Request) bool {
+	if req.Body == nil || req.Body == http.NoBody {
+		return false
+	}
+
+	if strings.HasPrefix(req.URL.Path, ollama.ApiPathBlobs) {
+		return true
+	}
+
+	content_type := req.Header.Get("Content-Type")
+	return content_type != "" && !strings.Contains(content_type, "json")
+}
+
+// uploadBody stands for an upload of size bytes in the capture file, the
+// digest is in the path
+func uploadBody(size int64) string {
+	return fmt.Sprintf("[upload of %d bytes]", size)
+}
+
+// countingBody counts what the transport reads without keeping it
+type countingBody struct {
+	io.ReadCloser
+	n atomic.Int64
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n.Add(int64(n))
+	return n, err
+}
+
+// recordingBody keeps what's read and hands it to done when closed
+type recordingBody struct {
+	io.ReadCloser
+	buf  bytes.Buffer
+	once sync.Once
+	done func([]byte)
+}
+
+func (b *recordingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.buf.Write(p[:n])
+	return n, err
+}
+
+func (b *recordingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() { b.done(b.buf.Bytes()) })
+	return err
+}
@@ -0,0 +1,142 @@
+package capture
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Replayer is a transport that serves the responses of a capture file,
+// no server is needed. Requests are matched by method, path and body,
+// preferring the same host, and each recorded response is served once in
+// order, the last one is repeated when they run out
+type Replayer struct {
+	mu        sync.Mutex
+	exchanges []*Exchange
+	served    []bool
+}
+
+// NewReplayer reads the capture file
+func NewReplayer(path string) (*Replayer, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening capture file: %+v", err)
+	}
+	defer file.Close()
+
+	r := &Replayer{}
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		e := &Exchange{}
+		if err := json.Unmarshal([]byte(line), e); err != nil {
+			return nil, fmt.Errorf("capture line %d: %+v", number, err)
+		}
+		r.exchanges = append(r.exchanges, e)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading capture file: %+v", err)
+	}
+
+	r.served = make([]bool, len(r.exchanges))
+
+	return r, nil
+}
+
+// Wrap returns the replayer itself, replayed requests never reach a server
+func (r *Replayer) Wrap(transport http.RoundTripper) http.RoundTripper {
+	return r
+}
+
+// RoundTrip answers with the recorded response, requests that weren't
+// recorded get a 404 with the reason, like the api errors
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	var (
+		body string
+		err  error
+	)
+
+	if isUpload(req) {
+		size, err := io.Copy(io.Discard, req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("reading request body: %+v", err)
+		}
+		body = uploadBody(size)
+	} else if body, err = readBody(req); err != nil {
+		return nil, err
+	}
+
+	e := r.find(req.Method, req.URL.Host, requestPath(req), body)
+	if e == nil {
+		message, _ := json.Marshal(map[string]string{"error": fmt.Sprintf("no recorded response for %s %s", req.Method, requestPath(req))})
+		return response(req, http.StatusNotFound, http.Header{"Content-Type": {"application/json"}}, string(message)), nil
+	}
+
+	return response(req, e.Response.StatusCode, e.Response.Header.Clone(), e.Response.Body), nil
+}
+
+func (r *Replayer) find(method string, host string, path string, body string) *Exchange {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var (
+		unserved = -1
+		last     = -1
+	)
+
+	for i, e := range r.exchanges {
+		if e.Request.Method != method || e.Request.Path != path || !sameBody(e.Request.Body, body) {
+			continue
+		}
+
+		same_host := e.Request.Host == host
+		if !r.served[i] && (unserved < 0 || same_host && r.exchanges[unserved].Request.Host != host) {
+			unserved = i
+		}
+		if last < 0 || same_host || r.exchanges[last].Request.Host != host {
+			last = i
+		}
+	}
+
+	switch {
+	case unserved >= 0:
+		r.served[unserved] = true
+		return r.exchanges[unserved]
+	case last >= 0:
+		return r.exchanges[last]
+	}
+
+	return nil
+}
+
+func response(req *http.Request, status int, header http.Header, body string) *http.Response {
+	if header == nil {
+		header = http.Header{}
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,298 @@
+package chat
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/padiazg/ollama-tools/internals/modelfile"
+	"github.com/padiazg/ollama-tools/internals/tools"
+	"github.com/padiazg/ollama-tools/models/ollama"
+)
+
+const help = `Available commands:
+  /system <text>          Set the system message
+  /set <parameter> <val>  Set a parameter, like /set num_ctx 8192
+  /save <file>            Save the conversation to a file
+  /load <file>            Load a conversation from a file
+  /clear                  Clear the conversation, keeping the system message
+  /bye                    Exit
+`
+
+// Session is a conversation with a model, the history is sent with every
+// message
+type Session struct {
+	Client   *ollama.Client
+	Model    string
+	System   string
+	Messages []ollama.Message
+	Options  map[string]any
+	Out      io.Writer
+
+	// serverNumCtx is the context the server runs the model with when
+	// num_ctx isn't set, -1 once asked and unknown
+	serverNumCtx int
+}
+
+func NewSession(client *ollama.Client, model string, out io.Writer) *Session {
+	return &Session{
+		Client:  client,
+		Model:   model,
+		Options: map[string]any{},
+		Out:     out,
+	}
+}
+
+// transcript is what /save writes, a chat request `simulate` can also read
+type transcript struct {
+	Model    string           `json:"model"`
+	Messages []ollama.Message `json:"messages"`
+	Options  map[string]any   `json:"options,omitempty"`
+}
+
+// Run reads messages and slash commands from in until /bye or the end of
+// the input
+func (s *Session) Run(ctx context.Context, in io.Reader) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	fmt.Fprintf(s.Out, "Chatting with %s, /? for help\n", s.Model)
+
+	for {
+		fmt.Fprint(s.Out, ">>> ")
+		if !scanner.Scan() {
+			fmt.Fprintln(s.Out)
+			return scanner.Err()
+		}
+
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+			continue
+		case line == "/bye" || line == "/exit":
+			return nil
+		case strings.HasPrefix(line, "/"):
+			if err := s.command(line); err != nil {
+				fmt.Fprintf(s.Out, "%+v\n", err)
+			}
+		default:
+			if err := s.Send(ctx, line); err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				fmt.Fprintf(s.Out, "\nerror: %+v\n", err)
+			}
+		}
+	}
+}
+
+func (s *Session) command(line string) error {
+	command, args, _ := strings.Cut(line, " ")
+	args = strings.TrimSpace(args)
+
+	switch command {
+	case "/?", "/help":
+		fmt.Fprint(s.Out, help)
+	case "/system":
+		s.System = args
+		fmt.Fprintln(s.Out, "Set system message.")
+	case "/set":
+		name, value, _ := strings.Cut(args, " ")
+		if name == "" || value == "" {
+			return fmt.Errorf("usage: /set <parameter> <value>")
+		}
+
+		v, err := modelfile.ParameterValue(name, strings.TrimSpace(value))
+		if err != nil {
+			return err
+		}
+		s.Options[name] = v
+		fmt.Fprintf(s.Out, "Set parameter '%s' to '%v'\n", name, v)
+	case "/save":
+		if args == "" {
+			return fmt.Errorf("usage: /save <file>")
+		}
+		if err := s.Save(args); err != nil {
+			return err
+		}
+		fmt.Fprintf(s.Out, "Saved conversation to %s\n", args)
+	case "/load":
+		if args == "" {
+			return fmt.Errorf("usage: /load <file>")
+		}
+		if err := s.Load(args); err != nil {
+			return err
+		}
+		fmt.Fprintf(s.Out, "Loaded %d messages from %s\n", len(s.Messages), args)
+	case "/clear":
+		s.Messages = nil
+		fmt.Fprintln(s.Out, "Cleared session context")
+	default:
+		return fmt.Errorf("unknown command %s, /? for help", command)
+	}
+
+	return nil
+}
+
+// Send adds content to the history and streams the reply to Out
+func (s *Session) Send(ctx context.Context, content string) error {
+	var (
+		reply   strings.Builder
+		metrics ollama.Metrics
+		req     = &ollama.ChatRequest{
+			Model:    s.Model,
+			Messages: s.history(ollama.Message{Role: "user", Content: content}),
+		}
+	)
+
+	if len(s.Options) > 0 {
+		req.Options = s.Options
+	}
+
+	err := s.Client.Chat(ctx, req, func(r *ollama.ChatResponse) error {
+		fmt.Fprint(s.Out, r.Message.Content)
+		reply.WriteString(r.Message.Content)
+		if r.Done {
+			metrics = r.Metrics
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.Messages = append(s.Messages,
+		ollama.Message{Role: "user", Content: content},
+		ollama.Message{Role: "assistant", Content: reply.String()},
+	)
+
+	if _, ok := s.Options["num_ctx"]; !ok && s.serverNumCtx == 0 {
+		s.serverNumCtx = s.askNumCtx(ctx)
+	}
+
+	fmt.Fprintf(s.Out, "\n\n%s\n\n", s.stats(&metrics))
+
+	return nil
+}
+
+// history returns the messages to send, with the system message first
+func (s *Session) history(next ollama.Message) []ollama.Message {
+	messages := make([]ollama.Message, 0, len(s.Messages)+2)
+	if s.System != "" {
+		messages = append(messages, ollama.Message{Role: "system", Content: s.System})
+	}
+	messages = append(messages, s.Messages...)
+	return append(messages, next)
+}
+
+// NumCtx returns the context window in use, the one set with /set num_ctx
+// or else the one the server runs the model with
+func (s *Session) NumCtx() int {
+	if n, ok := s.Options["num_ctx"].(int); ok && n > 0 {
+		return n
+	}
+	if s.serverNumCtx > 0 {
+		return s.serverNumCtx
+	}
+	return tools.DefaultNumCtx
+}
+
+// askNumCtx returns the context of the loaded model from `/api/ps`, servers
+// that don't report it are asked for the num_ctx PARAMETER of the model. It
+// returns -1 when neither knows it
+func (s *Session) askNumCtx(ctx context.Context) int {
+	name := s.Model
+	if !strings.Contains(name, ":") {
+		name += ":latest"
+	}
+
+	if list, err := s.Client.Ps(ctx); err == nil {
+		for _, p := range list.Models {
+			if (p.Name == name || p.Model == name) && p.ContextLength > 0 {
+				return p.ContextLength
+			}
+		}
+	}
+
+	model, err := s.Client.Show(ctx, name)
+	if err != nil {
+		return -1
+	}
+
+	mf, err := modelfile.Parse(strings.NewReader(model.Modelfile))
+	if err != nil {
+		return -1
+	}
+
+	if n := mf.IntParameter("num_ctx", 0); n > 0 {
+		return n
+	}
+
+	return -1
+}
+
+func (s *Session) stats(m *ollama.Metrics) string {
+	var (
+		num_ctx = s.NumCtx()
+		used    = m.PromptEvalCount + m.EvalCount
+	)
+
+	return fmt.Sprintf("eval_count: %d, %.2f tokens/s, context: %d/%d tokens used, %d left",
+		m.EvalCount, m.TokensPerSecond(), used, num_ctx, max(num_ctx-used, 0))
+}
+
+// Save writes the conversation as a chat request
+func (s *Session) Save(path string) error {
+	t := &transcript{Model: s.Model, Messages: s.history(ollama.Message{})}
+	t.Messages = t.Messages[:len(t.Messages)-1]
+	if len(s.Options) > 0 {
+		t.Options = s.Options
+	}
+
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding conversation: %+v", err)
+	}
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("writing %s: %+v", path, err)
+	}
+
+	return nil
+}
+
+// Load replaces the conversation with one saved by Save
+func (s *Session) Load(path string) error {
+	t := &transcript{}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading %s: %+v", path, err)
+	}
+
+	if err := json.Unmarshal(data, t); err != nil {
+		return fmt.Errorf("decoding %s: %+v", path, err)
+	}
+
+	s.System, s.Messages, s.Options = "", nil, map[string]any{}
+	for _, m := range t.Messages {
+		if m.Role == "system" {
+			s.System = m.Content
+			continue
+		}
+		s.Messages = append(s.Messages, m)
+	}
+
+	// json numbers come back as float64, convert them like /set does
+	for name, value := range t.Options {
+		if v, err := modelfile.ParameterValue(name, fmt.Sprint(value)); err == nil {
+			s.Options[name] = v
+		}
+	}
+
+	return nil
+}
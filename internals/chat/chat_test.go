@@ -0,0 +1,187 @@
+package chat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/padiazg/ollama-tools/internals/tools"
+	"github.com/padiazg/ollama-tools/models/ollama"
+	"github.com/padiazg/ollama-tools/models/ollama/ollamatest"
+	"github.com/stretchr/testify/assert"
+)
+
+// chatClient talks to a fake server where the model, with a num_ctx of
+// 16384, always replies "fine thanks". Every chat request is recorded
+func chatClient(t *testing.T, requests *[]*ollama.ChatRequest, faults ...*ollamatest.Fault) *ollama.Client {
+	t.Helper()
+
+	var (
+		server = ollamatest.New(&ollamatest.Fixture{
+			Models: []*ollamatest.Model{{Name: "llama3.1", ContextLength: 131072, NumCtx: 16384, Response: "fine thanks"}},
+			Errors: faults,
+		})
+		fake = server.Transport()
+	)
+
+	return ollama.NewClient("http://ollama.test", ollama.WithTransport(roundTripper(func(r *http.Request) (*http.Response, error) {
+		if r.URL.Path == ollama.ApiPathChat {
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				return nil, err
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			req := &ollama.ChatRequest{}
+			if err := json.Unmarshal(body, req); err != nil {
+				return nil, err
+			}
+			*requests = append(*requests, req)
+		}
+
+		return fake.RoundTrip(r)
+	})))
+}
+
+type roundTripper func(r *http.Request) (*http.Response, error)
+
+func (fn roundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	return fn(r)
+}
+
+func TestSession_Run(t *testing.T) {
+	var (
+		requests []*ollama.ChatRequest
+		client   = chatClient(t, &requests)
+		out      = &bytes.Buffer{}
+		saved    = filepath.Join(t.TempDir(), "chat.json")
+		session  = NewSession(client, "llama3.1:latest", out)
+		input    = strings.Join([]string{
+			"/system be brief",
+			"hello",
+			"/set num_ctx 8192",
+			"how are you?",
+			"/save " + saved,
+			"/clear",
+			"again",
+			"/load " + saved,
+			"/set num_ctx lots",
+			"/nope",
+			"/bye",
+			"never sent",
+		}, "\n")
+	)
+	defer client.Close()
+
+	err := session.Run(context.Background(), strings.NewReader(input))
+	if !assert.NoError(t, err) || !assert.Len(t, requests, 3) {
+		return
+	}
+
+	// system + user
+	assert.Equal(t, []ollama.Message{{Role: "system", Content: "be brief"}, {Role: "user", Content: "hello"}}, requests[0].Messages)
+	assert.Nil(t, requests[0].Options)
+
+	// system + first turn + user, with the options
+	assert.Len(t, requests[1].Messages, 4)
+	assert.Equal(t, "fine thanks", requests[1].Messages[2].Content)
+	assert.Equal(t, map[string]any{"num_ctx": float64(8192)}, requests[1].Options)
+
+	// cleared, system + user
+	assert.Len(t, requests[2].Messages, 2)
+
+	// loaded back the two turns saved before clearing
+	assert.Len(t, session.Messages, 4)
+	assert.Equal(t, "be brief", session.System)
+	assert.Equal(t, 8192, session.NumCtx())
+
+	output := out.String()
+	assert.Contains(t, output, "fine thanks")
+	assert.Contains(t, output, "eval_count: 2, 50.00 tokens/s, context: 5/16384 tokens used, 16379 left", "the context the server loaded")
+	assert.Contains(t, output, "context: 10/8192 tokens used, 8182 left")
+	assert.Contains(t, output, "Loaded 4 messages from")
+	assert.Contains(t, output, "num_ctx must be an integer")
+	assert.Contains(t, output, "unknown command /nope")
+	assert.NotContains(t, output, "never sent")
+}
+
+func TestSession_NumCtx(t *testing.T) {
+	tests := []struct {
+		name   string
+		faults []*ollamatest.Fault
+		want   int
+	}{
+		{
+			name: "from-ps",
+			want: 16384,
+		},
+		{
+			name:   "from-show",
+			faults: []*ollamatest.Fault{{Path: ollama.ApiPathPs, Status: http.StatusNotFound, Message: "404 page not found"}},
+			want:   16384,
+		},
+		{
+			name: "unknown",
+			faults: []*ollamatest.Fault{
+				{Path: ollama.ApiPathPs, Status: http.StatusNotFound, Message: "404 page not found"},
+				{Path: ollama.ApiPathShow, Message: "show failed"},
+			},
+			want: tools.DefaultNumCtx,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				requests []*ollama.ChatRequest
+				client   = chatClient(t, &requests, tt.faults...)
+				session  = NewSession(client, "llama3.1", io.Discard)
+			)
+			defer client.Close()
+
+			assert.Equal(t, tools.DefaultNumCtx, session.NumCtx(), "not asked before the first reply")
+			if assert.NoError(t, session.Send(context.Background(), "hello")) {
+				assert.Equal(t, tt.want, session.NumCtx())
+			}
+		})
+	}
+}
+
+func TestSession_Load(t *testing.T) {
+	var (
+		saved   = filepath.Join(t.TempDir(), "chat.json")
+		session = NewSession(nil, "llama3.1", io.Discard)
+	)
+
+	session.Messages = []ollama.Message{{Role: "user", Content: "hello"}, {Role: "assistant", Content: "hi"}}
+	if !assert.NoError(t, session.Save(saved)) {
+		return
+	}
+
+	session.System = "be brief"
+	session.Options["num_ctx"] = 8192
+	if assert.NoError(t, session.Load(saved)) {
+		assert.Empty(t, session.System)
+		assert.Empty(t, session.Options, "options the file doesn't have are dropped")
+		assert.Len(t, session.Messages, 2)
+	}
+}
+
+func TestSession_Send_error(t *testing.T) {
+	var (
+		requests []*ollama.ChatRequest
+		client   = chatClient(t, &requests)
+		out      = &bytes.Buffer{}
+		session  = NewSession(client, "missing", out)
+	)
+	defer client.Close()
+
+	err := session.Send(context.Background(), "hello")
+	assert.ErrorContains(t, err, "model 'missing:latest' not found")
+	assert.Empty(t, session.Messages, "failed turns are not kept")
+}
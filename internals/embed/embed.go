@@ -0,0 +1,260 @@
+package embed
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/padiazg/ollama-tools/models/ollama"
+)
+
+const (
+	FormatJSONL = "jsonl"
+	FormatCSV   = "csv"
+	FormatF32   = "f32"
+
+	DefaultBatchSize   = 32
+	DefaultConcurrency = 2
+)
+
+// Result holds the embeddings in input order and how long they took
+type Result struct {
+	Embeddings [][]float32
+	Tokens     int
+	Duration   time.Duration
+}
+
+// Embed sends the inputs in batches, at most concurrency at a time
+func Embed(ctx context.Context, client *ollama.Client, model string, inputs []string, batch_size int, concurrency int) (*Result, error) {
+	var (
+		result = &Result{Embeddings: make([][]float32, len(inputs))}
+		start  = time.Now()
+		sem    = make(chan struct{}, max(concurrency, 1))
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		first  error
+	)
+
+	batch_size = max(batch_size, 1)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	for offset := 0; offset < len(inputs); offset += batch_size {
+		var (
+			end   = min(offset+batch_size, len(inputs))
+			batch = inputs[offset:end]
+		)
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(offset int, batch []string) {
+			defer func() { <-sem; wg.Done() }()
+
+			res, err := client.Embed(ctx, &ollama.EmbedRequest{Model: model, Input: batch})
+			if err == nil && len(res.Embeddings) != len(batch) {
+				err = fmt.Errorf("got %d embeddings for %d inputs", len(res.Embeddings), len(batch))
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				if first == nil {
+					first = fmt.Errorf("embedding inputs %d to %d: %+v", offset, offset+len(batch)-1, err)
+					cancel()
+				}
+				return
+			}
+
+			copy(result.Embeddings[offset:], res.Embeddings)
+			result.Tokens += res.PromptEvalCount
+		}(offset, batch)
+	}
+
+	wg.Wait()
+
+	if first != nil {
+		return nil, first
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	result.Duration = time.Since(start)
+
+	return result, nil
+}
+
+// CheckFormat tells if the embeddings can be written as format
+func CheckFormat(format string) error {
+	switch format {
+	case FormatJSONL, FormatCSV, FormatF32:
+		return nil
+	}
+
+	return fmt.Errorf("unknown format %s, use jsonl, csv or f32", format)
+}
+
+// Write writes the embeddings as JSON lines, CSV rows or raw little-endian
+// float32 values, one vector after the other
+func Write(w io.Writer, format string, embeddings [][]float32) error {
+	if err := CheckFormat(format); err != nil {
+		return err
+	}
+
+	switch format {
+	case FormatJSONL:
+		enc := json.NewEncoder(w)
+		for i, e := range embeddings {
+			if err := enc.Encode(map[string]any{"index": i, "embedding": e}); err != nil {
+				return err
+			}
+		}
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		for i, e := range embeddings {
+			record := make([]string, 0, len(e)+1)
+			record = append(record, strconv.Itoa(i))
+			for _, v := range e {
+				record = append(record, strconv.FormatFloat(float64(v), 'g', -1, 32))
+			}
+			if err := cw.Write(record); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	case FormatF32:
+		for _, e := range embeddings {
+			if err := binary.Write(w, binary.LittleEndian, e); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Stats summarizes the embeddings, Dimensions is 0 when the vectors don't
+// all have the same length
+type Stats struct {
+	Count      int
+	Dimensions int
+	MinNorm    float64
+	MeanNorm   float64
+	MaxNorm    float64
+}
+
+func NewStats(embeddings [][]float32) *Stats {
+	s := &Stats{Count: len(embeddings), MinNorm: math.Inf(1)}
+	if len(embeddings) == 0 {
+		s.MinNorm = 0
+		return s
+	}
+
+	s.Dimensions = len(embeddings[0])
+	for _, e := range embeddings {
+		if len(e) != s.Dimensions {
+			s.Dimensions = 0
+		}
+
+		var sum float64
+		for _, v := range e {
+			sum += float64(v) * float64(v)
+		}
+
+		norm := math.Sqrt(sum)
+		s.MeanNorm += norm
+		s.MinNorm = math.Min(s.MinNorm, norm)
+		s.MaxNorm = math.Max(s.MaxNorm, norm)
+	}
+	s.MeanNorm /= float64(len(embeddings))
+
+	return s
+}
+
+// Report prints the stats and throughput, and checks the dimensions against
+// the `embedding_length` of the model when it's known
+func Report(w io.Writer, result *Result, embedding_length int) error {
+	s := NewStats(result.Embeddings)
+	seconds := result.Duration.Seconds()
+
+	fmt.Fprintf(w, "Embeddings: %d\n", s.Count)
+	fmt.Fprintf(w, "  Dimensions: %d\n", s.Dimensions)
+	fmt.Fprintf(w, "  Norm: min %.4f, mean %.4f, max %.4f\n", s.MinNorm, s.MeanNorm, s.MaxNorm)
+	if seconds > 0 {
+		fmt.Fprintf(w, "  Throughput: %.2f inputs/s, %.2f tokens/s (%s)\n",
+			float64(s.Count)/seconds, float64(result.Tokens)/seconds, result.Duration.Round(time.Millisecond))
+	}
+
+	if s.Dimensions == 0 && s.Count > 0 {
+		return fmt.Errorf("embeddings have different lengths")
+	}
+
+	if embedding_length > 0 && s.Dimensions != embedding_length {
+		return fmt.Errorf("embeddings have %d dimensions, the model reports an embedding_length of %d", s.Dimensions, embedding_length)
+	}
+
+	return nil
+}
+
+// Config tells how to send the inputs and how to write the embeddings
+type Config struct {
+	BatchSize   int
+	Concurrency int
+	Format      string
+}
+
+// Run embeds the inputs of src with model, writes the vectors to out and
+// the report to report
+func Run(ctx context.Context, client *ollama.Client, model string, src *Source, cfg *Config, out io.Writer, report io.Writer) error {
+	if err := CheckFormat(cfg.Format); err != nil {
+		return err
+	}
+
+	inputs, err := ReadInputs(src)
+	if err != nil {
+		return err
+	}
+
+	if err := client.Require(ctx, ollama.FeatureEmbed); err != nil {
+		return err
+	}
+
+	// the checks are skipped when the model can't be read
+	embedding_length := 0
+	if m, err := client.Show(ctx, model); err == nil {
+		embedding_length = m.ModelInfo.EmbeddingLength
+
+		if len(m.Capabilities) > 0 && !m.HasCapability("embedding") {
+			return fmt.Errorf("%s is not an embedding model, its capabilities are %s", model, strings.Join(m.Capabilities, ", "))
+		}
+	}
+
+	result, err := Embed(ctx, client, model, inputs, cfg.BatchSize, cfg.Concurrency)
+	if err != nil {
+		return err
+	}
+
+	if err := Write(out, cfg.Format, result.Embeddings); err != nil {
+		return fmt.Errorf("writing embeddings: %+v", err)
+	}
+
+	return Report(report, result, embedding_length)
+}
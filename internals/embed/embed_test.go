@@ -0,0 +1,246 @@
+package embed
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/padiazg/ollama-tools/models/ollama"
+	"github.com/stretchr/testify/assert"
+)
+
+// embedServer returns a 2 dimensions vector for every input, {len(input), 0},
+// and reports an embedding_length of 2. It keeps the batch sizes and the
+// most requests seen at the same time
+type embedServer struct {
+	version string
+	mu      sync.Mutex
+	batches []int
+	active  int
+	peak    int
+}
+
+func (s *embedServer) start(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case ollama.ApiPathVersion:
+			_, _ = w.Write([]byte(`{"version":"` + s.version + `"}`))
+			return
+		case ollama.ApiPathShow:
+			req := &ollama.ShowRequest{}
+			_ = json.NewDecoder(r.Body).Decode(req)
+			if req.Model == "llama3.1" {
+				_, _ = w.Write([]byte(`{"details":{"family":"llama"},"capabilities":["completion","tools"]}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"details":{"family":"bert"},"model_info":{"general.architecture":"bert","bert.embedding_length":2},"capabilities":["embedding"]}`))
+			return
+		}
+
+		req := &ollama.EmbedRequest{}
+		_ = json.NewDecoder(r.Body).Decode(req)
+
+		if req.Model == "missing" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error":"model \"missing\" not found, try pulling it first"}`))
+			return
+		}
+
+		s.mu.Lock()
+		s.batches = append(s.batches, len(req.Input))
+		s.active++
+		s.peak = max(s.peak, s.active)
+		s.mu.Unlock()
+
+		defer func() {
+			s.mu.Lock()
+			s.active--
+			s.mu.Unlock()
+		}()
+
+		res := &ollama.EmbedResponse{Model: req.Model, PromptEvalCount: len(req.Input)}
+		for _, input := range req.Input {
+			res.Embeddings = append(res.Embeddings, []float32{float32(len(input)), 0})
+		}
+		_ = json.NewEncoder(w).Encode(res)
+	}))
+}
+
+func TestEmbed(t *testing.T) {
+	var (
+		server = &embedServer{}
+		ts     = server.start(t)
+		client = ollama.NewClient(ts.URL)
+		inputs = []string{"a", "bb", "ccc", "dddd", "eeeee", "ffffff", "ggggggg"}
+	)
+	defer ts.Close()
+
+	got, err := Embed(context.Background(), client, "nomic-embed-text", inputs, 2, 2)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for i, e := range got.Embeddings {
+		assert.Equal(t, []float32{float32(i + 1), 0}, e, "embeddings are in input order")
+	}
+	assert.Equal(t, 7, got.Tokens)
+	assert.ElementsMatch(t, []int{2, 2, 2, 1}, server.batches)
+	assert.LessOrEqual(t, server.peak, 2)
+
+	_, err = Embed(context.Background(), client, "missing", inputs, 2, 2)
+	assert.ErrorContains(t, err, `model "missing" not found`)
+}
+
+func TestRun(t *testing.T) {
+	var (
+		server = &embedServer{version: "0.6.5"}
+		ts     = server.start(t)
+		client = ollama.NewClient(ts.URL)
+		out    = &bytes.Buffer{}
+		report = &bytes.Buffer{}
+	)
+	defer ts.Close()
+
+	err := Run(context.Background(), client, "nomic-embed-text", &Source{Texts: []string{"abc", "abcd"}}, &Config{Format: FormatJSONL}, out, report)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "{\"embedding\":[3,0],\"index\":0}\n{\"embedding\":[4,0],\"index\":1}\n", out.String())
+	assert.Contains(t, report.String(), "Dimensions: 2")
+	assert.Contains(t, report.String(), "Norm: min 3.0000, mean 3.5000, max 4.0000")
+
+	err = Run(context.Background(), client, "llama3.1", &Source{Texts: []string{"abc"}}, &Config{Format: FormatJSONL}, out, report)
+	assert.ErrorContains(t, err, "llama3.1 is not an embedding model, its capabilities are completion, tools")
+
+	// the format is checked before anything is sent
+	batches := len(server.batches)
+	err = Run(context.Background(), client, "nomic-embed-text", &Source{Texts: []string{"abc"}}, &Config{Format: "xml"}, out, report)
+	assert.ErrorContains(t, err, "unknown format xml")
+	assert.Equal(t, batches, len(server.batches))
+
+	server.version = "0.2.8"
+	err = Run(context.Background(), ollama.NewClient(ts.URL), "nomic-embed-text", &Source{Texts: []string{"abc"}}, &Config{Format: FormatJSONL}, out, report)
+	assert.ErrorContains(t, err, "/api/embed requires Ollama >= 0.3.0, the server is 0.2.8")
+}
+
+func TestWrite(t *testing.T) {
+	embeddings := [][]float32{{1, 0.5}, {-2, 0}}
+
+	tests := []struct {
+		name    string
+		format  string
+		want    string
+		wantErr string
+	}{
+		{name: "jsonl", format: FormatJSONL, want: "{\"embedding\":[1,0.5],\"index\":0}\n{\"embedding\":[-2,0],\"index\":1}\n"},
+		{name: "csv", format: FormatCSV, want: "0,1,0.5\n1,-2,0\n"},
+		{name: "unknown", format: "xml", wantErr: "unknown format xml"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &bytes.Buffer{}
+			err := Write(w, tt.format, embeddings)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.want, w.String())
+			}
+		})
+	}
+
+	t.Run("f32", func(t *testing.T) {
+		w := &bytes.Buffer{}
+		if !assert.NoError(t, Write(w, FormatF32, embeddings)) || !assert.Equal(t, 16, w.Len()) {
+			return
+		}
+
+		data := w.Bytes()
+		for i, want := range []float32{1, 0.5, -2, 0} {
+			got := math.Float32frombits(binary.LittleEndian.Uint32(data[i*4:]))
+			assert.Equal(t, want, got)
+		}
+	})
+}
+
+func TestReport(t *testing.T) {
+	tests := []struct {
+		name             string
+		embeddings       [][]float32
+		embedding_length int
+		wantErr          string
+	}{
+		{name: "matches", embeddings: [][]float32{{3, 4}, {0, 1}}, embedding_length: 2},
+		{name: "unknown-length", embeddings: [][]float32{{3, 4}}, embedding_length: 0},
+		{name: "mismatch", embeddings: [][]float32{{3, 4}}, embedding_length: 768, wantErr: "embeddings have 2 dimensions, the model reports an embedding_length of 768"},
+		{name: "ragged", embeddings: [][]float32{{3, 4}, {1}}, embedding_length: 2, wantErr: "different lengths"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Report(&bytes.Buffer{}, &Result{Embeddings: tt.embeddings}, tt.embedding_length)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+
+	s := NewStats([][]float32{{3, 4}, {0, 1}})
+	assert.Equal(t, &Stats{Count: 2, Dimensions: 2, MinNorm: 1, MeanNorm: 3, MaxNorm: 5}, s)
+}
+
+func TestReadInputs(t *testing.T) {
+	var (
+		dir   = t.TempDir()
+		file  = filepath.Join(dir, "doc.txt")
+		csv   = filepath.Join(dir, "rows.csv")
+		jsonl = filepath.Join(dir, "rows.jsonl")
+	)
+
+	_ = os.WriteFile(file, []byte("from a file"), 0o644)
+	_ = os.WriteFile(csv, []byte("id,text\n1,first row\n2,\"second, quoted\"\n"), 0o644)
+	_ = os.WriteFile(jsonl, []byte("{\"id\":1,\"text\":\"first\"}\n\n{\"id\":2,\"text\":\"second\"}\n"), 0o644)
+
+	tests := []struct {
+		name    string
+		src     *Source
+		want    []string
+		wantErr string
+	}{
+		{name: "texts-and-files", src: &Source{Texts: []string{"hello"}, Files: []string{file}}, want: []string{"hello", "from a file"}},
+		{name: "csv", src: &Source{Table: csv, Column: "text"}, want: []string{"first row", "second, quoted"}},
+		{name: "jsonl", src: &Source{Table: jsonl, Column: "text"}, want: []string{"first", "second"}},
+		{name: "csv-missing-column", src: &Source{Table: csv, Column: "body"}, wantErr: "column body not found"},
+		{name: "jsonl-not-a-string", src: &Source{Table: jsonl, Column: "id"}, wantErr: "line 1: no string field id"},
+		{name: "no-column", src: &Source{Table: jsonl}, wantErr: "a column is needed"},
+		{name: "empty", src: &Source{}, wantErr: "no inputs given"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ReadInputs(tt.src)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
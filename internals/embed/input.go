@@ -0,0 +1,137 @@
+package embed
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Source tells where to read the inputs from, every text and every file is
+// an input, and so is every row of the Column in the JSONL/CSV Table
+type Source struct {
+	Texts  []string
+	Files  []string
+	Table  string
+	Column string
+}
+
+// ReadInputs collects the inputs in order: texts, files, then table rows
+func ReadInputs(src *Source) ([]string, error) {
+	inputs := append([]string{}, src.Texts...)
+
+	for _, file := range src.Files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %+v", file, err)
+		}
+		inputs = append(inputs, string(data))
+	}
+
+	if src.Table != "" {
+		rows, err := readTable(src.Table, src.Column)
+		if err != nil {
+			return nil, err
+		}
+		inputs = append(inputs, rows...)
+	}
+
+	if len(inputs) == 0 {
+		return nil, fmt.Errorf("no inputs given")
+	}
+
+	return inputs, nil
+}
+
+// readTable reads a column from a .csv file, with a header row, or from a
+// file of JSON lines
+func readTable(path string, column string) ([]string, error) {
+	if column == "" {
+		return nil, fmt.Errorf("a column is needed to read %s", path)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %+v", path, err)
+	}
+	defer f.Close()
+
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return readCSVColumn(f, column)
+	}
+
+	return readJSONLColumn(f, column)
+}
+
+func readCSVColumn(r io.Reader, column string) ([]string, error) {
+	var (
+		rows   = []string{}
+		reader = csv.NewReader(r)
+		index  = -1
+	)
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading csv header: %+v", err)
+	}
+
+	for i, name := range header {
+		if name == column {
+			index = i
+		}
+	}
+
+	if index < 0 {
+		return nil, fmt.Errorf("column %s not found in csv header", column)
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading csv: %+v", err)
+		}
+		rows = append(rows, record[index])
+	}
+}
+
+func readJSONLColumn(r io.Reader, column string) ([]string, error) {
+	var (
+		rows    = []string{}
+		scanner = bufio.NewScanner(r)
+		number  = 0
+	)
+
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	for scanner.Scan() {
+		number++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		record := map[string]any{}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			return nil, fmt.Errorf("line %d: %+v", number, err)
+		}
+
+		value, ok := record[column].(string)
+		if !ok {
+			return nil, fmt.Errorf("line %d: no string field %s", number, column)
+		}
+		rows = append(rows, value)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading jsonl: %+v", err)
+	}
+
+	return rows, nil
+}
@@ -0,0 +1,245 @@
+package modelfile
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	CommandFrom      = "from"
+	CommandAdapter   = "adapter"
+	CommandTemplate  = "template"
+	CommandSystem    = "system"
+	CommandParameter = "parameter"
+	CommandLicense   = "license"
+	CommandMessage   = "message"
+	CommandRequires  = "requires"
+)
+
+var commands = map[string]bool{
+	CommandFrom:      true,
+	CommandAdapter:   true,
+	CommandTemplate:  true,
+	CommandSystem:    true,
+	CommandParameter: true,
+	CommandLicense:   true,
+	CommandMessage:   true,
+	CommandRequires:  true,
+}
+
+// Command is an instruction of a Modelfile. For PARAMETER and MESSAGE the
+// Name holds the parameter name or the role
+type Command struct {
+	Line    int
+	Command string
+	Name    string
+	Args    string
+}
+
+type Modelfile struct {
+	Commands []Command
+}
+
+// ParseFile parses the Modelfile at path
+func ParseFile(path string) (*Modelfile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %+v", path, err)
+	}
+	defer f.Close()
+
+	return Parse(f)
+}
+
+// Parse reads a Modelfile. Values can be bare, "quoted" or """triple quoted"""
+// spanning several lines, lines starting with # are comments
+func Parse(r io.Reader) (*Modelfile, error) {
+	var (
+		mf      = &Modelfile{}
+		scanner = bufio.NewScanner(r)
+		number  = 0
+	)
+
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		number++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		command, rest, _ := strings.Cut(line, " ")
+		command = strings.ToLower(command)
+		if !commands[command] {
+			return nil, fmt.Errorf("line %d: unknown command %s", number, command)
+		}
+
+		c := Command{Line: number, Command: command}
+		rest = strings.TrimSpace(rest)
+
+		if command == CommandParameter || command == CommandMessage {
+			c.Name, rest, _ = strings.Cut(rest, " ")
+			c.Name = strings.ToLower(c.Name)
+			rest = strings.TrimSpace(rest)
+		}
+
+		switch {
+		case strings.HasPrefix(rest, `"""`):
+			value, err := readTripleQuoted(strings.TrimPrefix(rest, `"""`), scanner, &number)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %+v", c.Line, err)
+			}
+			c.Args = value
+		case strings.HasPrefix(rest, `"`):
+			value, err := strconv.Unquote(rest)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid quoted value %s", c.Line, rest)
+			}
+			c.Args = value
+		default:
+			c.Args = rest
+		}
+
+		if c.Args == "" && command != CommandSystem && command != CommandTemplate {
+			return nil, fmt.Errorf("line %d: missing value for %s", c.Line, strings.ToUpper(command))
+		}
+
+		mf.Commands = append(mf.Commands, c)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading modelfile: %+v", err)
+	}
+
+	return mf, nil
+}
+
+// readTripleQuoted reads until the closing """, which may be on the same line
+func readTripleQuoted(first string, scanner *bufio.Scanner, number *int) (string, error) {
+	if value, _, found := strings.Cut(first, `"""`); found {
+		return value, nil
+	}
+
+	lines := []string{first}
+	for scanner.Scan() {
+		*number++
+		line := scanner.Text()
+		if value, _, found := strings.Cut(line, `"""`); found {
+			lines = append(lines, value)
+			return strings.Join(lines, "\n"), nil
+		}
+		lines = append(lines, line)
+	}
+
+	return "", fmt.Errorf(`unterminated """`)
+}
+
+// Get returns the value of the last command of the given kind
+func (mf *Modelfile) Get(command string) string {
+	value := ""
+	for _, c := range mf.Commands {
+		if c.Command == command {
+			value = c.Args
+		}
+	}
+	return value
+}
+
+// All returns every value of the given kind, in order
+func (mf *Modelfile) All(command string) []string {
+	values := []string{}
+	for _, c := range mf.Commands {
+		if c.Command == command {
+			values = append(values, c.Args)
+		}
+	}
+	return values
+}
+
+// Parameters returns the PARAMETERs with their values converted to the type
+// Ollama expects, repeated ones like `stop` are collected in a list
+func (mf *Modelfile) Parameters() (map[string]any, error) {
+	params := map[string]any{}
+
+	for _, c := range mf.Commands {
+		if c.Command != CommandParameter {
+			continue
+		}
+
+		value, err := ParameterValue(c.Name, c.Args)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %+v", c.Line, err)
+		}
+
+		if listParameters[c.Name] {
+			list, _ := params[c.Name].([]string)
+			params[c.Name] = append(list, value.(string))
+			continue
+		}
+
+		params[c.Name] = value
+	}
+
+	return params, nil
+}
+
+var (
+	listParameters = map[string]bool{"stop": true}
+	intParameters  = map[string]bool{
+		"num_ctx": true, "num_gpu": true, "num_predict": true, "num_batch": true, "num_keep": true,
+		"num_thread": true, "seed": true, "top_k": true, "repeat_last_n": true, "mirostat": true,
+		"main_gpu": true,
+	}
+	floatParameters = map[string]bool{
+		"temperature": true, "top_p": true, "min_p": true, "typical_p": true, "repeat_penalty": true,
+		"presence_penalty": true, "frequency_penalty": true, "mirostat_tau": true, "mirostat_eta": true,
+	}
+	boolParameters = map[string]bool{
+		"use_mmap": true, "use_mlock": true, "numa": true, "penalize_newline": true,
+	}
+)
+
+// ParameterValue converts a PARAMETER value to its type, unknown names are
+// kept as strings for the server to validate
+func ParameterValue(name string, value string) (any, error) {
+	switch {
+	case intParameters[name]:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be an integer, got %s", name, value)
+		}
+		return n, nil
+	case floatParameters[name]:
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a number, got %s", name, value)
+		}
+		return n, nil
+	case boolParameters[name]:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be true or false, got %s", name, value)
+		}
+		return b, nil
+	}
+
+	return value, nil
+}
+
+// IntParameter returns an integer PARAMETER, or def if it's not set
+func (mf *Modelfile) IntParameter(name string, def int) int {
+	value := def
+	for _, c := range mf.Commands {
+		if c.Command == CommandParameter && c.Name == name {
+			if n, err := strconv.Atoi(c.Args); err == nil {
+				value = n
+			}
+		}
+	}
+	return value
+}
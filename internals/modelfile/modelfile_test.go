@@ -0,0 +1,88 @@
+package modelfile
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const sample = `# a custom coder
+FROM qwen2.5-coder:7b
+ADAPTER ./adapter.gguf
+
+PARAMETER num_ctx 16384
+PARAMETER temperature 0.2
+parameter stop "<|im_end|>"
+PARAMETER stop <|endoftext|>
+PARAMETER use_mmap false
+
+SYSTEM """You are a careful
+senior engineer."""
+TEMPLATE """{{ .Prompt }}"""
+MESSAGE user "Hi"
+MESSAGE assistant Hello, how can I help?
+LICENSE """Apache 2.0"""
+`
+
+func TestParse(t *testing.T) {
+	mf, err := Parse(strings.NewReader(sample))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "qwen2.5-coder:7b", mf.Get(CommandFrom))
+	assert.Equal(t, []string{"./adapter.gguf"}, mf.All(CommandAdapter))
+	assert.Equal(t, "You are a careful\nsenior engineer.", mf.Get(CommandSystem))
+	assert.Equal(t, "{{ .Prompt }}", mf.Get(CommandTemplate))
+	assert.Equal(t, []string{"Apache 2.0"}, mf.All(CommandLicense))
+	assert.Equal(t, 16384, mf.IntParameter("num_ctx", 2048))
+	assert.Equal(t, -1, mf.IntParameter("num_gpu", -1))
+
+	params, err := mf.Parameters()
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]any{
+			"num_ctx":     16384,
+			"temperature": 0.2,
+			"stop":        []string{"<|im_end|>", "<|endoftext|>"},
+			"use_mmap":    false,
+		}, params)
+	}
+
+	messages := []Command{}
+	for _, c := range mf.Commands {
+		if c.Command == CommandMessage {
+			messages = append(messages, c)
+		}
+	}
+	if assert.Len(t, messages, 2) {
+		assert.Equal(t, "user", messages[0].Name)
+		assert.Equal(t, "Hi", messages[0].Args)
+		assert.Equal(t, "Hello, how can I help?", messages[1].Args)
+		assert.Equal(t, 15, messages[1].Line)
+	}
+}
+
+func TestParse_errors(t *testing.T) {
+	tests := []struct {
+		name         string
+		modelfile    string
+		wantErrorMsg string
+	}{
+		{name: "unknown-command", modelfile: "FROM llama3\nRUN rm -rf /\n", wantErrorMsg: "line 2: unknown command run"},
+		{name: "unterminated", modelfile: "FROM llama3\nSYSTEM \"\"\"hello\n", wantErrorMsg: `line 2: unterminated """`},
+		{name: "missing-value", modelfile: "FROM\n", wantErrorMsg: "line 1: missing value for FROM"},
+		{name: "bad-quotes", modelfile: "SYSTEM \"hi\" there\n", wantErrorMsg: "invalid quoted value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.modelfile))
+			assert.ErrorContains(t, err, tt.wantErrorMsg)
+		})
+	}
+
+	mf, _ := Parse(strings.NewReader("FROM llama3\nPARAMETER num_ctx lots\n"))
+	_, err := mf.Parameters()
+	assert.ErrorContains(t, err, "line 2: num_ctx must be an integer")
+}
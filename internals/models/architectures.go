@@ -0,0 +1,180 @@
+package models
+
+import (
+	_ "embed"
+	"encoding/json"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/padiazg/ollama-tools/models/ollama"
+)
+
+// architectures.json is a list of well known models with the values
+// /api/show reports for them, used when a model comes with incomplete metadata
+//
+//go:embed architectures.json
+var architecturesData []byte
+
+var architectures = loadArchitectures()
+
+type Architecture struct {
+	Family            string `json:"family"`
+	Name              string `json:"name"`
+	ParameterCount    int64  `json:"parameter_count"`
+	BlockCount        int    `json:"block_count"`
+	EmbeddingLength   int    `json:"embedding_length"`
+	FeedForwardLength int    `json:"feed_forward_length"`
+	HeadCount         int    `json:"head_count"`
+	HeadCountKV       int    `json:"head_count_kv"`
+	KeyLength         int    `json:"key_length"`
+	ContextLength     int    `json:"context_length"`
+}
+
+func loadArchitectures() []Architecture {
+	list := []Architecture{}
+	if err := json.Unmarshal(architecturesData, &list); err != nil {
+		panic("decoding architectures.json: " + err.Error())
+	}
+
+	return list
+}
+
+// ParseParameterSize converts a `details.parameter_size` like 8.0B, 137M or
+// 8x7B into a parameter count, 0 if it can't be parsed
+func ParseParameterSize(size string) int64 {
+	var (
+		multiplier float64 = 1
+		experts    float64 = 1
+	)
+
+	size = strings.ToUpper(strings.TrimSpace(size))
+	if size == "" {
+		return 0
+	}
+
+	if e, rest, found := strings.Cut(size, "X"); found {
+		n, err := strconv.ParseFloat(e, 64)
+		if err != nil {
+			return 0
+		}
+		experts, size = n, rest
+	}
+
+	if size == "" {
+		return 0
+	}
+
+	switch size[len(size)-1] {
+	case 'T':
+		multiplier = 1_000_000_000_000
+	case 'B':
+		multiplier = 1_000_000_000
+	case 'M':
+		multiplier = 1_000_000
+	case 'K':
+		multiplier = 1_000
+	}
+
+	if multiplier > 1 {
+		size = size[:len(size)-1]
+	}
+
+	n, err := strconv.ParseFloat(size, 64)
+	if err != nil {
+		return 0
+	}
+
+	return int64(experts * n * multiplier)
+}
+
+// FindArchitecture returns the known model of the family closest in size to
+// parameter_count. Unknown families are matched against every known model
+func FindArchitecture(family string, parameter_count int64) *Architecture {
+	var (
+		found    *Architecture
+		distance = math.Inf(1)
+		pool     = []*Architecture{}
+	)
+
+	if parameter_count <= 0 {
+		return nil
+	}
+
+	for i := range architectures {
+		if architectures[i].Family == family {
+			pool = append(pool, &architectures[i])
+		}
+	}
+
+	if len(pool) == 0 {
+		for i := range architectures {
+			pool = append(pool, &architectures[i])
+		}
+	}
+
+	for _, a := range pool {
+		d := math.Abs(math.Log(float64(parameter_count) / float64(a.ParameterCount)))
+		if d < distance {
+			found, distance = a, d
+		}
+	}
+
+	return found
+}
+
+// InferModelInfo fills the values missing in the model info from the
+// architectures database, every filled field is recorded in Inferred
+func InferModelInfo(model *ollama.Model) {
+	var (
+		info   = &model.ModelInfo
+		family = model.Details.Family
+	)
+
+	if family == "" && len(model.Details.Families) > 0 {
+		family = model.Details.Families[0]
+	}
+
+	if info.ParameterCount == 0 {
+		if info.ParameterCount = ParseParameterSize(model.Details.ParameterSize); info.ParameterCount > 0 {
+			info.Inferred = append(info.Inferred, ollama.FieldParameterCount)
+		}
+	}
+
+	if info.ContextLength > 0 && info.EmbeddingLength > 0 && info.BlockCount > 0 && info.HeadCount > 0 {
+		return
+	}
+
+	arch := FindArchitecture(family, info.ParameterCount)
+	if arch == nil {
+		return
+	}
+
+	infer := func(field string, value *int, known int) {
+		if *value == 0 && known > 0 {
+			*value = known
+			info.Inferred = append(info.Inferred, field)
+		}
+	}
+
+	infer(ollama.FieldContextLength, &info.ContextLength, arch.ContextLength)
+	infer(ollama.FieldEmbeddingLength, &info.EmbeddingLength, arch.EmbeddingLength)
+	infer(ollama.FieldBlockCount, &info.BlockCount, arch.BlockCount)
+
+	if info.FeedForwardLength == 0 && arch.FeedForwardLength > 0 {
+		info.FeedForwardLength = ollama.FlexInt(arch.FeedForwardLength)
+		info.Inferred = append(info.Inferred, ollama.FieldFeedForwardLength)
+	}
+
+	// the attention layout goes as a whole, a reported head_count without
+	// head_count_kv just means there's no grouped query attention
+	if info.HeadCount == 0 {
+		info.HeadCount = ollama.FlexInt(arch.HeadCount)
+		info.HeadCountKV = ollama.FlexInt(arch.HeadCountKV)
+		info.Inferred = append(info.Inferred, ollama.FieldHeadCount, ollama.FieldHeadCountKV)
+		if info.KeyLength == 0 && arch.KeyLength > 0 {
+			info.KeyLength = arch.KeyLength
+			info.Inferred = append(info.Inferred, ollama.FieldKeyLength)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/padiazg/ollama-tools/models/ollama"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseParameterSize(t *testing.T) {
+	tests := []struct {
+		size string
+		want int64
+	}{
+		{size: "8.0B", want: 8_000_000_000},
+		{size: "137M", want: 137_000_000},
+		{size: "14.7B", want: 14_700_000_000},
+		{size: "8x7B", want: 56_000_000_000},
+		{size: "8x", want: 0},
+		{size: "1234", want: 1234},
+		{size: "", want: 0},
+		{size: "big", want: 0},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.size, func(t *testing.T) {
+			assert.Equal(t, tt.want, ParseParameterSize(tt.size))
+		})
+	}
+}
+
+func TestFindArchitecture(t *testing.T) {
+	tests := []struct {
+		name            string
+		family          string
+		parameter_count int64
+		want            string
+	}{
+		{name: "exact", family: "llama", parameter_count: 8030261312, want: "llama3.1:8b"},
+		{name: "nearest", family: "qwen2", parameter_count: 7_600_000_000, want: "qwen2.5:7b"},
+		{name: "family-wins", family: "gemma2", parameter_count: 8_000_000_000, want: "gemma2:9b"},
+		{name: "unknown-family", family: "unknown", parameter_count: 136_000_000, want: "nomic-embed-text:137m"},
+		{name: "no-size", family: "llama", parameter_count: 0, want: ""},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got := FindArchitecture(tt.family, tt.parameter_count)
+			if tt.want == "" {
+				assert.Nil(t, got)
+				return
+			}
+
+			if assert.NotNil(t, got) {
+				assert.Equal(t, tt.want, got.Name)
+			}
+		})
+	}
+}
+
+func TestInferModelInfo(t *testing.T) {
+	t.Run("complete", func(t *testing.T) {
+		model := &ollama.Model{
+			Details:   modelsList[modelPhi4].model.Details,
+			ModelInfo: modelsList[modelPhi4].model.ModelInfo,
+		}
+
+		InferModelInfo(model)
+		assert.Equal(t, modelsList[modelPhi4].model.ModelInfo, model.ModelInfo)
+		assert.Empty(t, model.ModelInfo.Inferred)
+	})
+
+	t.Run("missing-metadata", func(t *testing.T) {
+		model := &ollama.Model{
+			Details: ollama.ModelDetails{Family: "llama", ParameterSize: "8.0B"},
+		}
+
+		InferModelInfo(model)
+		info := model.ModelInfo
+		assert.Equal(t, int64(8_000_000_000), info.ParameterCount)
+		assert.Equal(t, 131072, info.ContextLength)
+		assert.Equal(t, 32, info.BlockCount)
+		assert.Equal(t, ollama.FlexInt(8), info.HeadCountKV)
+		assert.True(t, info.IsInferred(ollama.FieldParameterCount))
+		assert.True(t, info.IsInferred(ollama.FieldContextLength))
+		assert.False(t, info.IsInferred(ollama.FieldKeyLength))
+	})
+
+	t.Run("partial", func(t *testing.T) {
+		model := &ollama.Model{
+			Details: ollama.ModelDetails{Families: []string{"qwen2"}},
+			ModelInfo: ollama.ModelInfo{
+				ParameterCount: 7615616512,
+				ContextLength:  131072,
+				HeadCount:      28,
+			},
+		}
+
+		InferModelInfo(model)
+		info := model.ModelInfo
+		assert.Equal(t, 131072, info.ContextLength, "reported values are kept")
+		assert.Equal(t, 3584, info.EmbeddingLength)
+		assert.Equal(t, ollama.FlexInt(0), info.HeadCountKV, "the reported attention layout is kept")
+		assert.ElementsMatch(t, []string{
+			ollama.FieldEmbeddingLength,
+			ollama.FieldBlockCount,
+			ollama.FieldFeedForwardLength,
+		}, info.Inferred)
+	})
+}
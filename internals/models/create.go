@@ -0,0 +1,335 @@
+package models
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/padiazg/ollama-tools/internals/modelfile"
+	"github.com/padiazg/ollama-tools/internals/tools"
+	"github.com/padiazg/ollama-tools/models/ollama"
+	"github.com/padiazg/ollama-tools/models/settings"
+)
+
+// ModelfileEstimate is the memory a Modelfile needs with its num_ctx and
+// num_gpu PARAMETERs
+type ModelfileEstimate struct {
+	Source            string
+	ParameterCount    int64
+	QuantizationLevel string
+	BlockCount        int
+	NumCtx            int
+	// NumGPU is the number of layers offloaded to the GPU, -1 for all
+	NumGPU     int
+	Estimation *ollama.MemoryEstimation
+	GPU        float64
+	CPU        float64
+}
+
+// Create builds a model from a Modelfile, printing the memory estimate
+// before and the resulting model after
+func Create(ctx context.Context, client *ollama.Client, hw settings.Hardware, name string, path string, quantize string, dry_run bool) {
+	mf, err := modelfile.ParseFile(path)
+	if err != nil {
+		fmt.Printf("parsing modelfile: %+v\n", err)
+		return
+	}
+
+	dir := filepath.Dir(path)
+	quantize = strings.ToUpper(quantize)
+
+	if est, err := EstimateModelfile(ctx, client, mf, dir, quantize); err != nil {
+		fmt.Printf("estimating %s: %+v\n", name, err)
+	} else {
+		printModelfileEstimate(est, hw)
+	}
+
+	if dry_run {
+		return
+	}
+
+	req, err := CreateRequest(ctx, client, name, mf, dir, quantize)
+	if err != nil {
+		fmt.Printf("preparing %s: %+v\n", name, err)
+		return
+	}
+
+	err = client.Create(ctx, req, func(p *ollama.ProgressResponse) error {
+		fmt.Println(p.Status)
+		return nil
+	})
+	if err != nil {
+		fmt.Printf("creating %s: %+v\n", name, err)
+		return
+	}
+
+	tags, err := GetTags(ctx, client)
+	if err != nil {
+		fmt.Printf("listing models: %+v\n", err)
+		return
+	}
+
+	if tag := findTag(tags.Models, name); tag != nil {
+		printTagModel(tag)
+	}
+}
+
+// EstimateModelfile estimates the memory for the FROM model with the
+// num_ctx and num_gpu PARAMETERs, split between GPU and CPU by layers
+func EstimateModelfile(ctx context.Context, client *ollama.Client, mf *modelfile.Modelfile, dir string, quantize string) (*ModelfileEstimate, error) {
+	from := mf.Get(modelfile.CommandFrom)
+	if from == "" {
+		return nil, fmt.Errorf("no FROM in modelfile")
+	}
+
+	est := &ModelfileEstimate{
+		Source: from,
+		NumCtx: mf.IntParameter("num_ctx", tools.DefaultNumCtx),
+		NumGPU: mf.IntParameter("num_gpu", -1),
+	}
+
+	if local, ok := localPath(from, dir); ok {
+		from = local
+	}
+
+	if src, err := GetCreateSource(ctx, client, from); err == nil {
+		est.ParameterCount = src.ParameterCount
+		est.QuantizationLevel = src.QuantizationLevel
+		est.BlockCount = src.BlockCount
+	} else {
+		est.ParameterCount, est.QuantizationLevel = guessFromTag(from)
+	}
+
+	if quantize != "" {
+		est.QuantizationLevel = quantize
+	}
+
+	if est.ParameterCount == 0 || est.QuantizationLevel == "" {
+		return nil, fmt.Errorf("can't size %s", est.Source)
+	}
+
+	est.Estimation = tools.EstimateMemory(est.ParameterCount, est.NumCtx, est.QuantizationLevel)
+	est.GPU, est.CPU = splitLayers(est.Estimation.GPURAM, est.NumGPU, est.BlockCount)
+
+	return est, nil
+}
+
+// splitLayers splits the memory between GPU and CPU by the share of layers
+// offloaded, when the layer count is unknown num_gpu is all or nothing
+func splitLayers(total float64, num_gpu int, block_count int) (float64, float64) {
+	switch {
+	case num_gpu == 0:
+		return 0, total
+	case num_gpu < 0 || block_count == 0 || num_gpu >= block_count:
+		return total, 0
+	}
+
+	gpu := total * float64(num_gpu) / float64(block_count)
+	return gpu, total - gpu
+}
+
+func printModelfileEstimate(est *ModelfileEstimate, hw settings.Hardware) {
+	layers := "all layers"
+	if est.NumGPU >= 0 {
+		layers = fmt.Sprintf("%d layers", est.NumGPU)
+		if est.BlockCount > 0 {
+			layers += fmt.Sprintf(" of %d", est.BlockCount)
+		}
+	}
+
+	fmt.Printf("From: %s\n", est.Source)
+	fmt.Printf("  Parameters: %s (%d)\n", tools.FormatParamCount(est.ParameterCount), est.ParameterCount)
+	fmt.Printf("  Quantization: %s\n", est.QuantizationLevel)
+	fmt.Printf("  num_ctx: %d, num_gpu: %s\n", est.NumCtx, layers)
+	tools.PrintEstimatedMemoryPlain(est.Estimation)
+	fmt.Printf("    On GPU: %.2f GB, on CPU: %.2f GB\n", est.GPU, est.CPU)
+
+	if hw.VRAM > 0 && est.GPU > hw.VRAM {
+		fmt.Printf("  Warning: needs %.2f GB of VRAM, only %.2f GB configured, lower num_gpu or num_ctx\n", est.GPU, hw.VRAM)
+	}
+	fmt.Println("")
+}
+
+func printTagModel(tag *ollama.TagModel) {
+	fmt.Printf("\nModel: %s\n", tag.Name)
+	fmt.Printf("  Digest: %s\n", shortDigest(tag.Digest))
+	fmt.Printf("  Size: %.2f GB\n", float64(tag.Size)/ONE_GB)
+	fmt.Printf("  Format: %s, Family: %s\n", tag.Details.Format, tag.Details.Family)
+	fmt.Printf("  Parameters: %s, Quantization: %s\n", tag.Details.ParameterSize, tag.Details.QuantizationLevel)
+}
+
+// CreateRequest turns the Modelfile into a create request, local FROM and
+// ADAPTER files are uploaded as blobs first
+func CreateRequest(ctx context.Context, client *ollama.Client, name string, mf *modelfile.Modelfile, dir string, quantize string) (*ollama.CreateRequest, error) {
+	var (
+		req = &ollama.CreateRequest{
+			Model:    name,
+			Template: mf.Get(modelfile.CommandTemplate),
+			System:   mf.Get(modelfile.CommandSystem),
+			License:  mf.All(modelfile.CommandLicense),
+			Quantize: quantize,
+		}
+		err error
+	)
+
+	if len(req.License) == 0 {
+		req.License = nil
+	}
+
+	if req.Parameters, err = mf.Parameters(); err != nil {
+		return nil, err
+	}
+
+	if len(req.Parameters) == 0 {
+		req.Parameters = nil
+	}
+
+	for _, c := range mf.Commands {
+		if c.Command == modelfile.CommandMessage {
+			req.Messages = append(req.Messages, ollama.Message{Role: c.Name, Content: c.Args})
+		}
+	}
+
+	from := mf.Get(modelfile.CommandFrom)
+	if from == "" {
+		return nil, fmt.Errorf("no FROM in modelfile")
+	}
+
+	if local, ok := localPath(from, dir); ok {
+		if req.Files, err = uploadBlobs(ctx, client, local); err != nil {
+			return nil, err
+		}
+	} else {
+		req.From = from
+	}
+
+	for _, adapter := range mf.All(modelfile.CommandAdapter) {
+		local, ok := localPath(adapter, dir)
+		if !ok {
+			return nil, fmt.Errorf("adapter %s not found", adapter)
+		}
+
+		files, err := uploadBlobs(ctx, client, local)
+		if err != nil {
+			return nil, err
+		}
+
+		if req.Adapters == nil {
+			req.Adapters = map[string]string{}
+		}
+		for k, v := range files {
+			req.Adapters[k] = v
+		}
+	}
+
+	return req, nil
+}
+
+// localPath resolves a FROM/ADAPTER value relative to the Modelfile, ok is
+// false when it's not a file or directory on disk, that is, a model name
+func localPath(value string, dir string) (string, bool) {
+	path := value
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[2:])
+		}
+	}
+
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		return "", false
+	}
+
+	return path, true
+}
+
+// uploadBlobs uploads a file, or every file in a directory, that the server
+// doesn't have yet. It returns the file names with their digests
+func uploadBlobs(ctx context.Context, client *ollama.Client, path string) (map[string]string, error) {
+	var (
+		files  = []string{}
+		digest = map[string]string{}
+	)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, fmt.Errorf("listing %s: %+v", path, err)
+		}
+
+		for _, e := range entries {
+			if e.Type().IsRegular() && !strings.HasPrefix(e.Name(), ".") {
+				files = append(files, filepath.Join(path, e.Name()))
+			}
+		}
+		sort.Strings(files)
+	} else {
+		files = append(files, path)
+	}
+
+	for _, file := range files {
+		d, err := fileDigest(file)
+		if err != nil {
+			return nil, err
+		}
+
+		exists, err := client.BlobExists(ctx, d)
+		if err != nil {
+			return nil, fmt.Errorf("checking blob %s: %+v", d, err)
+		}
+
+		if !exists {
+			fmt.Printf("uploading %s\n", filepath.Base(file))
+			if err := uploadFile(ctx, client, file, d); err != nil {
+				return nil, err
+			}
+		}
+
+		digest[filepath.Base(file)] = d
+	}
+
+	return digest, nil
+}
+
+func uploadFile(ctx context.Context, client *ollama.Client, file string, digest string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := client.CreateBlob(ctx, digest, f); err != nil {
+		return fmt.Errorf("uploading %s: %+v", file, err)
+	}
+
+	return nil
+}
+
+// fileDigest returns the `sha256:<hex>` digest of a file
+func fileDigest(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("hashing %s: %+v", file, err)
+	}
+
+	return fmt.Sprintf("sha256:%x", h.Sum(nil)), nil
+}
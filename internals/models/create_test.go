@@ -0,0 +1,178 @@
+package models
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/padiazg/ollama-tools/internals/modelfile"
+	"github.com/padiazg/ollama-tools/models/ollama"
+	"github.com/padiazg/ollama-tools/models/ollama/ollamatest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateRequest(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		uploaded = map[string]string{}
+		existing = map[string]bool{}
+		created  *ollama.CreateRequest
+		ts       = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			switch {
+			case strings.HasPrefix(r.URL.Path, ollama.ApiPathBlobs):
+				digest := strings.TrimPrefix(r.URL.Path, ollama.ApiPathBlobs)
+				if r.Method == http.MethodHead {
+					if !existing[digest] {
+						w.WriteHeader(http.StatusNotFound)
+					}
+					return
+				}
+
+				data, _ := io.ReadAll(r.Body)
+				if fmt.Sprintf("sha256:%x", sha256.Sum256(data)) != digest {
+					w.WriteHeader(http.StatusBadRequest)
+					_, _ = w.Write([]byte(`{"error":"digest mismatch"}`))
+					return
+				}
+				uploaded[digest] = string(data)
+				w.WriteHeader(http.StatusCreated)
+
+			case r.URL.Path == ollama.ApiPathCreate:
+				created = &ollama.CreateRequest{}
+				_ = json.NewDecoder(r.Body).Decode(created)
+				_, _ = io.WriteString(w, `{"status":"using existing layer"}`+"\n"+`{"status":"success"}`+"\n")
+			}
+		}))
+		client = ollama.NewClient(ts.URL)
+		dir    = t.TempDir()
+	)
+	defer ts.Close()
+
+	_ = os.WriteFile(filepath.Join(dir, "model.gguf"), []byte("weights"), 0o644)
+	_ = os.WriteFile(filepath.Join(dir, "adapter.gguf"), []byte("adapter"), 0o644)
+	existing[fmt.Sprintf("sha256:%x", sha256.Sum256([]byte("adapter")))] = true
+
+	t.Run("local-files", func(t *testing.T) {
+		mf, err := modelfile.Parse(strings.NewReader("FROM ./model.gguf\nADAPTER adapter.gguf\nPARAMETER num_ctx 8192\nSYSTEM hi\nMESSAGE user hello\n"))
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		req, err := CreateRequest(context.Background(), client, "mine:latest", mf, dir, "Q4_K_M")
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		weights := fmt.Sprintf("sha256:%x", sha256.Sum256([]byte("weights")))
+		assert.Equal(t, map[string]string{weights: "weights"}, uploaded, "only the missing blob is uploaded")
+		assert.Equal(t, map[string]string{"model.gguf": weights}, req.Files)
+		assert.Equal(t, map[string]string{"adapter.gguf": fmt.Sprintf("sha256:%x", sha256.Sum256([]byte("adapter")))}, req.Adapters)
+		assert.Empty(t, req.From)
+		assert.Equal(t, map[string]any{"num_ctx": 8192}, req.Parameters)
+		assert.Equal(t, []ollama.Message{{Role: "user", Content: "hello"}}, req.Messages)
+
+		statuses := []string{}
+		err = client.Create(context.Background(), req, func(p *ollama.ProgressResponse) error {
+			statuses = append(statuses, p.Status)
+			return nil
+		})
+		if assert.NoError(t, err) {
+			assert.Equal(t, []string{"using existing layer", "success"}, statuses)
+			assert.Equal(t, "mine:latest", created.Model)
+			assert.Equal(t, "Q4_K_M", created.Quantize)
+			assert.Equal(t, float64(8192), created.Parameters["num_ctx"])
+		}
+	})
+
+	t.Run("from-model", func(t *testing.T) {
+		mf, _ := modelfile.Parse(strings.NewReader("FROM llama3.1:8b\n"))
+
+		req, err := CreateRequest(context.Background(), client, "mine:latest", mf, dir, "")
+		if assert.NoError(t, err) {
+			assert.Equal(t, "llama3.1:8b", req.From)
+			assert.Nil(t, req.Files)
+			assert.Nil(t, req.Parameters)
+		}
+	})
+
+	t.Run("missing-adapter", func(t *testing.T) {
+		mf, _ := modelfile.Parse(strings.NewReader("FROM llama3.1:8b\nADAPTER ./nope.gguf\n"))
+
+		_, err := CreateRequest(context.Background(), client, "mine:latest", mf, dir, "")
+		assert.ErrorContains(t, err, "adapter ./nope.gguf not found")
+	})
+}
+
+func TestEstimateModelfile(t *testing.T) {
+	var (
+		client = tagsList.filter([]string{modelPhi4}).getClient(nil)
+		phi4   = modelsList[modelPhi4].model
+	)
+
+	mf, _ := modelfile.Parse(strings.NewReader("FROM phi4:latest\nPARAMETER num_ctx 8192\nPARAMETER num_gpu 10\n"))
+
+	got, err := EstimateModelfile(context.Background(), client, mf, ".", "")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, phi4.ModelInfo.ParameterCount, got.ParameterCount)
+	assert.Equal(t, "Q4_K_M", got.QuantizationLevel)
+	assert.Equal(t, 8192, got.NumCtx)
+	assert.Equal(t, phi4.ModelInfo.BlockCount, got.BlockCount)
+	assert.InDelta(t, got.Estimation.GPURAM*10/float64(phi4.ModelInfo.BlockCount), got.GPU, 1e-9)
+	assert.InDelta(t, got.Estimation.GPURAM, got.GPU+got.CPU, 1e-9)
+}
+
+func TestGetCreateSource(t *testing.T) {
+	client := ollama.NewClient("http://ollama:11434", ollama.WithTransport(ollamatest.New(nil).Transport()))
+	defer client.Close()
+
+	for _, name := range []string{"phi4", "phi4:latest"} {
+		t.Run(name, func(t *testing.T) {
+			got, err := GetCreateSource(context.Background(), client, name)
+			if assert.NoError(t, err) {
+				assert.Equal(t, int64(9053116391), got.SizeBytes)
+				assert.Equal(t, int64(14659507200), got.ParameterCount)
+			}
+		})
+	}
+
+	_, err := GetCreateSource(context.Background(), client, "nosuch")
+	assert.Error(t, err)
+}
+
+func Test_splitLayers(t *testing.T) {
+	tests := []struct {
+		name        string
+		num_gpu     int
+		block_count int
+		gpu, cpu    float64
+	}{
+		{name: "default", num_gpu: -1, block_count: 32, gpu: 8},
+		{name: "cpu-only", num_gpu: 0, block_count: 32, cpu: 8},
+		{name: "half", num_gpu: 16, block_count: 32, gpu: 4, cpu: 4},
+		{name: "more-than-layers", num_gpu: 99, block_count: 32, gpu: 8},
+		{name: "unknown-layers", num_gpu: 16, block_count: 0, gpu: 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gpu, cpu := splitLayers(8, tt.num_gpu, tt.block_count)
+			assert.Equal(t, tt.gpu, gpu)
+			assert.Equal(t, tt.cpu, cpu)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/padiazg/ollama-tools/internals/tools"
+	"github.com/padiazg/ollama-tools/models/ollama"
+)
+
+// Where the context length of an estimate comes from
+const (
+	ContextFromFlag    = "flag"
+	ContextFromServer  = "server"
+	ContextFromDefault = "default"
+)
+
+// ModelEstimate is the memory a model on the server needs for a context
+type ModelEstimate struct {
+	Name              string
+	Model             *ollama.Model
+	QuantizationLevel string
+	ContextLength     int
+	ContextSource     string
+	Memory            *ollama.MemoryEstimation
+}
+
+// EstimateModel looks the model up on the backend and estimates its memory.
+// A context_length or quantization_level given override the ones of the
+// server, the context defaults to the one the server runs the model with
+func EstimateModel(ctx context.Context, backend ollama.Backend, name string, context_length int, quantization_level string) (*ModelEstimate, error) {
+	model, err := GetModelInfo(ctx, backend, name)
+	if err != nil {
+		return nil, err
+	}
+
+	est := &ModelEstimate{
+		Name:              name,
+		Model:             model,
+		QuantizationLevel: strings.ToUpper(quantization_level),
+		ContextLength:     context_length,
+		ContextSource:     ContextFromFlag,
+	}
+
+	if est.QuantizationLevel == "" {
+		est.QuantizationLevel = model.Details.QuantizationLevel
+	}
+
+	if est.QuantizationLevel == "" {
+		return nil, fmt.Errorf("the server doesn't tell the quantization level of %s, give it with --quantization-level", name)
+	}
+
+	if model.ModelInfo.ParameterCount == 0 {
+		return nil, fmt.Errorf("the server doesn't tell the parameter count of %s, use --parameter-count without a model", name)
+	}
+
+	switch {
+	case est.ContextLength > 0:
+	case model.NumCtx > 0:
+		est.ContextLength, est.ContextSource = model.NumCtx, ContextFromServer
+	default:
+		est.ContextLength, est.ContextSource = tools.DefaultNumCtx, ContextFromDefault
+	}
+
+	est.Memory = tools.EstimateMemory(model.ModelInfo.ParameterCount, est.ContextLength, est.QuantizationLevel)
+
+	return est, nil
+}
+
+// PrintModelEstimate prints the values the estimate was made with
+func PrintModelEstimate(est *ModelEstimate) {
+	info := est.Model.ModelInfo
+
+	fmt.Printf("Model: %s\n", est.Name)
+	fmt.Printf("  Parameters: %s (%d)%s\n", tools.FormatParamCount(info.ParameterCount), info.ParameterCount, inferredNote(&info, ollama.FieldParameterCount))
+	fmt.Printf("  Quantization: %s\n", est.QuantizationLevel)
+	fmt.Printf("  Context Length: %d tokens (%s)", est.ContextLength, est.ContextSource)
+	if info.ContextLength > 0 {
+		fmt.Printf(", trained with %d%s", info.ContextLength, inferredNote(&info, ollama.FieldContextLength))
+	}
+	fmt.Println()
+
+	tools.PrintEstimatedMemoryPlain(est.Memory)
+}
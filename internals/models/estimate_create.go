@@ -0,0 +1,98 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/padiazg/ollama-tools/internals/tools"
+	"github.com/padiazg/ollama-tools/internals/weights"
+	"github.com/padiazg/ollama-tools/models/ollama"
+)
+
+// CreateSource describes what `ollama create` will be reading from
+type CreateSource struct {
+	Name              string
+	Format            string
+	ParameterCount    int64
+	QuantizationLevel string
+	SizeBytes         int64
+	LargestTensor     int64
+	BlockCount        int
+}
+
+// EstimateCreate prints the resources needed to quantize source, an installed
+// model or a GGUF/safetensors file, to quantization_level
+func EstimateCreate(ctx context.Context, client *ollama.Client, source string, quantization_level string) {
+	if err := tools.CheckQuantizationLevel(quantization_level); err != nil {
+		fmt.Printf("estimating create: %+v\n", err)
+		return
+	}
+
+	src, err := GetCreateSource(ctx, client, source)
+	if err != nil {
+		fmt.Printf("estimating create: %+v\n", err)
+		return
+	}
+
+	quantization_level = strings.ToUpper(quantization_level)
+	est := tools.EstimateCreate(src.SizeBytes, src.ParameterCount, src.LargestTensor, quantization_level)
+
+	fmt.Printf("Source: %s (%s)\n", src.Name, src.Format)
+	fmt.Printf("  Parameters: %s (%d)\n", tools.FormatParamCount(src.ParameterCount), src.ParameterCount)
+	fmt.Printf("  Quantization: %s -> %s\n", src.QuantizationLevel, quantization_level)
+	tools.PrintEstimatedCreatePlain(est)
+}
+
+// GetCreateSource reads the source header from disk when source is a path,
+// otherwise it looks it up as an installed model
+func GetCreateSource(ctx context.Context, client *ollama.Client, source string) (*CreateSource, error) {
+	if _, err := os.Stat(source); err == nil {
+		h, err := weights.Read(source)
+		if err != nil {
+			return nil, err
+		}
+
+		return &CreateSource{
+			Name:              source,
+			Format:            h.Format,
+			ParameterCount:    h.ParameterCount,
+			QuantizationLevel: h.QuantizationLevel,
+			SizeBytes:         h.SizeBytes,
+			LargestTensor:     h.LargestTensor,
+			BlockCount:        h.BlockCount(),
+		}, nil
+	}
+
+	model, err := GetModelInfo(ctx, client, source)
+	if err != nil {
+		return nil, fmt.Errorf("getting model %s info: %+v", source, err)
+	}
+
+	tags, err := GetTags(ctx, client)
+	if err != nil {
+		return nil, fmt.Errorf("getting tags: %+v", err)
+	}
+
+	src := &CreateSource{
+		Name:              source,
+		Format:            "installed",
+		ParameterCount:    model.ModelInfo.ParameterCount,
+		QuantizationLevel: model.Details.QuantizationLevel,
+		BlockCount:        model.ModelInfo.BlockCount,
+	}
+
+	for _, tag := range tags.Models {
+		if withTag(tag.Name) == withTag(source) || withTag(tag.Model) == withTag(source) {
+			src.SizeBytes = int64(tag.Size)
+			break
+		}
+	}
+
+	if src.SizeBytes == 0 {
+		return nil, fmt.Errorf("model %s not found in tags", source)
+	}
+
+	return src, nil
+}
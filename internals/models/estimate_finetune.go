@@ -0,0 +1,84 @@
+package models
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/padiazg/ollama-tools/internals/tools"
+	"github.com/padiazg/ollama-tools/models/ollama"
+)
+
+const defaultFinetuneQuantization = "F16"
+
+// EstimateFinetune prints the memory needed to fine-tune a model with
+// LoRA/QLoRA. When model_name is given the missing values in ft are taken
+// from the installed model
+func EstimateFinetune(ctx context.Context, client *ollama.Client, model_name string, ft *tools.FinetuneConfig) {
+	if model_name != "" {
+		model, err := GetModelInfo(ctx, client, model_name)
+		if err != nil {
+			fmt.Printf("getting model %s info: %+v\n", model_name, err)
+			return
+		}
+
+		fillFinetuneConfig(ft, model)
+		fmt.Printf("Model: %s\n", model_name)
+	}
+
+	if ft.QuantizationLevel == "" {
+		ft.QuantizationLevel = defaultFinetuneQuantization
+	}
+
+	est, err := tools.EstimateFinetune(ft)
+	if err != nil {
+		fmt.Printf("estimating fine-tuning: %+v\n", err)
+		return
+	}
+
+	fmt.Printf("  Parameters: %s (%d)\n", tools.FormatParamCount(ft.ParameterCount), ft.ParameterCount)
+	fmt.Printf("  Base Quantization: %s\n", ft.QuantizationLevel)
+	fmt.Printf("  Rank: %d, Target Modules: %v\n", ft.Rank, ft.TargetModules)
+	fmt.Printf("  Batch Size: %d, Sequence Length: %d\n", ft.BatchSize, ft.SequenceLength)
+	tools.PrintEstimatedFinetunePlain(est)
+}
+
+// fillFinetuneConfig completes the values not set by the user with the ones
+// reported by the model
+func fillFinetuneConfig(ft *tools.FinetuneConfig, model *ollama.Model) {
+	var (
+		arch = &ft.Architecture
+		info = &model.ModelInfo
+	)
+
+	if ft.ParameterCount == 0 {
+		ft.ParameterCount = info.ParameterCount
+	}
+
+	if ft.QuantizationLevel == "" {
+		ft.QuantizationLevel = model.Details.QuantizationLevel
+	}
+
+	if arch.BlockCount == 0 {
+		arch.BlockCount = info.BlockCount
+	}
+
+	if arch.EmbeddingLength == 0 {
+		arch.EmbeddingLength = info.EmbeddingLength
+	}
+
+	if arch.FeedForwardLength == 0 {
+		arch.FeedForwardLength = info.FeedForwardLength
+	}
+
+	if arch.HeadCount == 0 {
+		arch.HeadCount = info.HeadCount
+	}
+
+	if arch.HeadCountKV == 0 {
+		arch.HeadCountKV = info.HeadCountKV
+	}
+
+	if arch.KeyLength == 0 {
+		arch.KeyLength = info.KeyLength
+	}
+}
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	"github.com/padiazg/ollama-tools/internals/tools"
+	"github.com/padiazg/ollama-tools/models/ollama"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeBackend serves a single model, like an OpenAI compatible server
+type fakeBackend struct {
+	model *ollama.Model
+}
+
+func (f *fakeBackend) Tags(ctx context.Context) (*ollama.Tags, error) {
+	return &ollama.Tags{Models: []ollama.TagModel{{Name: "coder"}}}, nil
+}
+
+func (f *fakeBackend) Show(ctx context.Context, model_name string) (*ollama.Model, error) {
+	m := *f.model
+	return &m, nil
+}
+
+func (f *fakeBackend) BaseUrl() string { return "http://llama-server:8080" }
+
+func (f *fakeBackend) Close() error { return nil }
+
+func TestEstimateModel(t *testing.T) {
+	tests := []struct {
+		name               string
+		model              *ollama.Model
+		context_length     int
+		quantization_level string
+		wantContext        int
+		wantSource         string
+		wantQuantization   string
+		wantErr            string
+	}{
+		{
+			name:             "server-context",
+			model:            &ollama.Model{Details: ollama.ModelDetails{QuantizationLevel: "Q4_K_M"}, ModelInfo: ollama.ModelInfo{ParameterCount: 7615616512}, NumCtx: 8192},
+			wantContext:      8192,
+			wantSource:       ContextFromServer,
+			wantQuantization: "Q4_K_M",
+		},
+		{
+			name:               "flags",
+			model:              &ollama.Model{Details: ollama.ModelDetails{QuantizationLevel: "Q4_K_M"}, ModelInfo: ollama.ModelInfo{ParameterCount: 7615616512}, NumCtx: 8192},
+			context_length:     16384,
+			quantization_level: "q8_0",
+			wantContext:        16384,
+			wantSource:         ContextFromFlag,
+			wantQuantization:   "Q8_0",
+		},
+		{
+			// the parameter count is inferred from the size in the name
+			name:             "inferred",
+			model:            &ollama.Model{Details: ollama.ModelDetails{ParameterSize: "7B", QuantizationLevel: "Q4_K_M"}},
+			wantContext:      tools.DefaultNumCtx,
+			wantSource:       ContextFromDefault,
+			wantQuantization: "Q4_K_M",
+		},
+		{
+			name:    "no-quantization",
+			model:   &ollama.Model{ModelInfo: ollama.ModelInfo{ParameterCount: 7615616512}},
+			wantErr: "give it with --quantization-level",
+		},
+		{
+			name:    "no-parameters",
+			model:   &ollama.Model{Details: ollama.ModelDetails{QuantizationLevel: "Q4_K_M"}},
+			wantErr: "use --parameter-count without a model",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EstimateModel(context.Background(), &fakeBackend{model: tt.model}, "coder", tt.context_length, tt.quantization_level)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			assert.Equal(t, tt.wantContext, got.ContextLength)
+			assert.Equal(t, tt.wantSource, got.ContextSource)
+			assert.Equal(t, tt.wantQuantization, got.QuantizationLevel)
+			assert.Equal(t, tools.EstimateMemory(got.Model.ModelInfo.ParameterCount, got.ContextLength, got.QuantizationLevel), got.Memory)
+		})
+	}
+}
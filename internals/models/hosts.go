@@ -0,0 +1,241 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/padiazg/ollama-tools/models/ollama"
+	"github.com/padiazg/ollama-tools/models/settings"
+)
+
+// Host is one of several servers queried at once
+type Host struct {
+	Name    string
+	Backend ollama.Backend
+}
+
+// HostError is a host that couldn't be queried
+type HostError struct {
+	Host  string
+	Error error
+}
+
+// HostsModelsInfoList runs ModelsInfoList on every host at the same time
+// and merges the models, in host order. Hosts that fail or can't be reached
+// are returned as errors and left out
+func HostsModelsInfoList(ctx context.Context, hosts []*Host, model_name string) ([]*ModelItem, []*HostError) {
+	var (
+		lists = make([][]*ModelItem, len(hosts))
+		errs  = make([]error, len(hosts))
+		wg    sync.WaitGroup
+	)
+
+	for i, host := range hosts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lists[i], errs[i] = ModelsInfoList(ctx, host.Backend, model_name)
+
+			// with a model name the tags aren't asked, a host that can't
+			// be reached fails on every model instead
+			for _, item := range lists[i] {
+				if ollama.Unreachable(item.Error) {
+					errs[i] = item.Error
+					break
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	items := []*ModelItem{}
+	failed := []*HostError{}
+	for i, host := range hosts {
+		if errs[i] != nil {
+			failed = append(failed, &HostError{Host: host.Name, Error: errs[i]})
+			continue
+		}
+
+		for _, item := range lists[i] {
+			item.Host = host.Name
+			items = append(items, item)
+		}
+	}
+
+	return items, failed
+}
+
+// HostsRunningModels runs RunningModels on every host at the same time,
+// only Ollama servers list the running models
+func HostsRunningModels(ctx context.Context, hosts []*Host) ([]*RunningModel, []*HostError) {
+	var (
+		lists = make([][]*RunningModel, len(hosts))
+		errs  = make([]error, len(hosts))
+		wg    sync.WaitGroup
+	)
+
+	for i, host := range hosts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client, ok := ollamaClient(host.Backend)
+			if !ok {
+				errs[i] = fmt.Errorf("ps needs the Ollama api")
+				return
+			}
+			lists[i], errs[i] = RunningModels(ctx, client)
+		}()
+	}
+	wg.Wait()
+
+	running := []*RunningModel{}
+	failed := []*HostError{}
+	for i, host := range hosts {
+		if errs[i] != nil {
+			failed = append(failed, &HostError{Host: host.Name, Error: errs[i]})
+			continue
+		}
+
+		for _, r := range lists[i] {
+			r.Host = host.Name
+			running = append(running, r)
+		}
+	}
+
+	return running, failed
+}
+
+// ollamaClient returns the Ollama client behind backend, if it's one
+func ollamaClient(backend ollama.Backend) (*ollama.Client, bool) {
+	for {
+		switch b := backend.(type) {
+		case *ollama.Client:
+			return b, true
+		case interface{ Unwrap() ollama.Backend }:
+			backend = b.Unwrap()
+		default:
+			return nil, false
+		}
+	}
+}
+
+// InventoryItem tells on which of the reachable hosts a model is
+type InventoryItem struct {
+	Name    string
+	Hosts   []string
+	Missing []string
+}
+
+// Inventory groups the models by name, hosts are the reachable ones.
+// Models that failed, like a name the host doesn't have, aren't on it
+func Inventory(items []*ModelItem, hosts []string) []*InventoryItem {
+	by_name := map[string]*InventoryItem{}
+	for _, item := range items {
+		if item.Error != nil {
+			continue
+		}
+
+		inv, ok := by_name[item.Name]
+		if !ok {
+			inv = &InventoryItem{Name: item.Name}
+			by_name[item.Name] = inv
+		}
+		if !slices.Contains(inv.Hosts, item.Host) {
+			inv.Hosts = append(inv.Hosts, item.Host)
+		}
+	}
+
+	inventory := make([]*InventoryItem, 0, len(by_name))
+	for _, inv := range by_name {
+		for _, host := range hosts {
+			if !slices.Contains(inv.Hosts, host) {
+				inv.Missing = append(inv.Missing, host)
+			}
+		}
+		inventory = append(inventory, inv)
+	}
+
+	sort.Slice(inventory, func(i, j int) bool { return inventory[i].Name < inventory[j].Name })
+
+	return inventory
+}
+
+// ListHosts prints the models of every host, with a summary of the models
+// found on several hosts or missing from some
+func ListHosts(ctx context.Context, hosts []*Host, cfg *settings.Settings, model_name string, table bool, chart bool) {
+	items, failed := HostsModelsInfoList(ctx, hosts, model_name)
+
+	switch {
+	case chart:
+		listModelsChart(cfg, items)
+	case table:
+		listModelsTable(items)
+	default:
+		listModelsDetail(items)
+	}
+
+	reachable := []string{}
+	for _, host := range hosts {
+		if !slices.ContainsFunc(failed, func(e *HostError) bool { return e.Host == host.Name }) {
+			reachable = append(reachable, host.Name)
+		}
+	}
+
+	printInventory(Inventory(items, reachable), len(reachable))
+	printHostErrors(failed)
+}
+
+// PsHosts prints the models loaded on every host
+func PsHosts(ctx context.Context, hosts []*Host, as_table bool) {
+	running, failed := HostsRunningModels(ctx, hosts)
+
+	switch {
+	case len(running) == 0:
+		fmt.Println("No models loaded")
+	case as_table:
+		psTable(running)
+	default:
+		psDetail(running)
+	}
+
+	printHostErrors(failed)
+}
+
+// printInventory lists the models that are on more than one host or not
+// on all of them
+func printInventory(inventory []*InventoryItem, hosts int) {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.SetTitle("Models across %d hosts", hosts)
+	t.AppendHeader(table.Row{"Model", "On", "Missing from"})
+
+	duplicated, partial := 0, 0
+	for _, inv := range inventory {
+		if len(inv.Hosts) > 1 {
+			duplicated++
+		}
+		if len(inv.Missing) > 0 {
+			partial++
+		}
+		if len(inv.Hosts) < 2 && len(inv.Missing) == 0 {
+			continue
+		}
+
+		t.AppendRow(table.Row{inv.Name, strings.Join(inv.Hosts, ", "), strings.Join(inv.Missing, ", ")})
+	}
+
+	t.AppendFooter(table.Row{fmt.Sprintf("%d models, %d on several hosts, %d missing from some", len(inventory), duplicated, partial)})
+	t.Render()
+}
+
+func printHostErrors(failed []*HostError) {
+	for _, e := range failed {
+		fmt.Printf("Host %s unreachable: %+v\n", e.Host, e.Error)
+	}
+}
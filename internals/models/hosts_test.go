@@ -0,0 +1,97 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/padiazg/ollama-tools/internals/cache"
+	"github.com/padiazg/ollama-tools/models/ollama"
+	"github.com/padiazg/ollama-tools/models/ollama/ollamatest"
+	"github.com/stretchr/testify/assert"
+)
+
+// hostClient talks to a fake server with only the named models of the
+// default fixture
+func hostClient(names ...string) *ollama.Client {
+	fixture := ollamatest.DefaultFixture()
+	fixture.Models = slices.DeleteFunc(fixture.Models, func(m *ollamatest.Model) bool { return !slices.Contains(names, m.Name) })
+
+	return ollama.NewClient("http://ollama:11434", ollama.WithTransport(ollamatest.New(fixture).Transport()))
+}
+
+func TestHostsModelsInfoList(t *testing.T) {
+	unreachable := &DryRunTransport{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		return nil, fmt.Errorf("test-unreachable")
+	}}
+
+	hosts := []*Host{
+		{Name: "gpu-1", Backend: hostClient(modelPhi4, modelLlama3_1)},
+		{Name: "gpu-2", Backend: hostClient(modelPhi4)},
+		{Name: "gpu-3", Backend: ollama.NewClient("http://gpu-3:11434", ollama.WithTransport(unreachable))},
+	}
+
+	got, failed := HostsModelsInfoList(context.Background(), hosts, "")
+	if !assert.Len(t, got, 3) || !assert.Len(t, failed, 1) {
+		return
+	}
+
+	assert.Equal(t, "gpu-3", failed[0].Host)
+	assert.ErrorContains(t, failed[0].Error, "test-unreachable")
+
+	// the models keep the host order
+	assert.Equal(t, "gpu-1", got[0].Host)
+	assert.Equal(t, "gpu-1", got[1].Host)
+	assert.Equal(t, "gpu-2", got[2].Host)
+	assert.Equal(t, modelPhi4, got[2].Name)
+	assert.True(t, withHosts(got))
+
+	t.Run("model-name", func(t *testing.T) {
+		got, failed := HostsModelsInfoList(context.Background(), hosts, modelPhi4)
+		if assert.Len(t, got, 2) && assert.Len(t, failed, 1) {
+			assert.Equal(t, "gpu-3", failed[0].Host, "unreachable without asking the tags")
+			assert.ErrorContains(t, failed[0].Error, "test-unreachable")
+		}
+	})
+}
+
+func TestHostsRunningModels(t *testing.T) {
+	var (
+		client = func(fixture *ollamatest.Fixture) *ollama.Client {
+			return ollama.NewClient("http://ollama:11434", ollama.WithTransport(ollamatest.New(fixture).Transport()))
+		}
+		hosts = []*Host{
+			{Name: "gpu-1", Backend: client(&ollamatest.Fixture{Errors: []*ollamatest.Fault{{Path: ollama.ApiPathPs, Message: "test-ps-error"}}})},
+			{Name: "gpu-2", Backend: cache.NewBackend(client(nil), cache.New(t.TempDir()))},
+			{Name: "llama-server", Backend: ollama.NewOpenAIBackend("http://llama-server:8080")},
+		}
+	)
+
+	got, failed := HostsRunningModels(context.Background(), hosts)
+	if assert.Len(t, got, 1) && assert.Len(t, failed, 2) {
+		assert.Equal(t, "gpu-2", got[0].Host)
+		assert.Equal(t, modelLlama3_1, got[0].Process.Name)
+		assert.Equal(t, "gpu-1", failed[0].Host)
+		assert.ErrorContains(t, failed[1].Error, "ps needs the Ollama api")
+	}
+}
+
+func TestInventory(t *testing.T) {
+	items := []*ModelItem{
+		{Name: modelPhi4, Host: "gpu-1"},
+		{Name: modelLlama3_1, Host: "gpu-1"},
+		{Name: modelPhi4, Host: "gpu-2"},
+		{Name: modelQwen, Host: "gpu-3"},
+		{Name: modelQwen, Host: "gpu-2", Error: fmt.Errorf("model '%s' not found", modelQwen)},
+	}
+
+	got := Inventory(items, []string{"gpu-1", "gpu-2", "gpu-3"})
+
+	assert.Equal(t, []*InventoryItem{
+		{Name: modelLlama3_1, Hosts: []string{"gpu-1"}, Missing: []string{"gpu-2", "gpu-3"}},
+		{Name: modelPhi4, Hosts: []string{"gpu-1", "gpu-2"}, Missing: []string{"gpu-3"}},
+		{Name: modelQwen, Hosts: []string{"gpu-3"}, Missing: []string{"gpu-1", "gpu-2"}},
+	}, got)
+}
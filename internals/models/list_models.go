@@ -1,6 +1,7 @@
 package models
 
 import (
+	"context"
 	"fmt"
 	"os"
 
@@ -12,15 +13,18 @@ import (
 )
 
 // List
-func List(cfg *settings.Settings, model_name string, table bool) {
-	models, err := ModelsInfoList(cfg, model_name)
+func List(ctx context.Context, client ollama.Backend, cfg *settings.Settings, model_name string, table bool, chart bool) {
+	models, err := ModelsInfoList(ctx, client, model_name)
 	if err != nil {
 		fmt.Printf("listing models: %+v", err)
 	}
 
-	if table {
+	switch {
+	case chart:
+		listModelsChart(cfg, models)
+	case table:
 		listModelsTable(models)
-	} else {
+	default:
 		listModelsDetail(models)
 	}
 }
@@ -34,17 +38,26 @@ func listModelsDetail(models []*ModelItem) {
 }
 
 func printModel(model *ModelItem) {
+	fmt.Printf("Model: %s\n", model.Name)
+	if model.Host != "" {
+		fmt.Printf("  Host: %s\n", model.Host)
+	}
+
+	if model.Error != nil {
+		fmt.Printf("  Error: %+v\n\n", model.Error)
+		return
+	}
+
 	modelInfo := model.Model.ModelInfo
 	details := model.Model.Details
-
-	fmt.Printf("Model: %s\n", model.Name)
-	fmt.Printf("  Parameters: %s (%d)\n",
+	fmt.Printf("  Parameters: %s (%d)%s\n",
 		tools.FormatParamCount(modelInfo.ParameterCount),
-		modelInfo.ParameterCount)
+		modelInfo.ParameterCount,
+		inferredNote(&modelInfo, ollama.FieldParameterCount))
 	fmt.Printf("  Quantization: %s\n", details.QuantizationLevel)
-	fmt.Printf("  Context Length: %d tokens\n", modelInfo.ContextLength)
+	fmt.Printf("  Context Length: %d tokens%s\n", modelInfo.ContextLength, inferredNote(&modelInfo, ollama.FieldContextLength))
 	if modelInfo.EmbeddingLength > 0 {
-		fmt.Printf("  Embedding Length: %d\n", modelInfo.EmbeddingLength)
+		fmt.Printf("  Embedding Length: %d%s\n", modelInfo.EmbeddingLength, inferredNote(&modelInfo, ollama.FieldEmbeddingLength))
 	}
 
 	mem := tools.EstimateMemory(modelInfo.ParameterCount, modelInfo.ContextLength, details.QuantizationLevel)
@@ -58,43 +71,110 @@ func printModel(model *ModelItem) {
 	fmt.Println("")
 }
 
+// inferredNote flags values that were not reported by the server
+func inferredNote(info *ollama.ModelInfo, field string) string {
+	if info.IsInferred(field) {
+		return " (inferred)"
+	}
+	return ""
+}
+
+// inferredMark flags table cells with values that were not reported by the server
+func inferredMark(info *ollama.ModelInfo, field string) string {
+	if info.IsInferred(field) {
+		return "*"
+	}
+	return ""
+}
+
 func listModelsTable(models []*ModelItem) {
-	t := table.NewWriter()
-	t.SetOutputMirror(os.Stdout)
-	t.AppendHeader(
-		table.Row{"Model", "Parameters", "Parameters", "Quantization", "Quantization", "Context Length", "Embedding Length", "Base Model Size", "KV Cache", "GPU RAM", "System RAM"},
-		table.RowConfig{AutoMerge: true},
-	)
-	t.AppendHeader(
-		table.Row{"", "Billions", "Units", "level", "bits", "", "", "", "", "", ""},
+	var (
+		t         = table.NewWriter()
+		hosts     = withHosts(models)
+		header    = table.Row{"Model", "Parameters", "Parameters", "Quantization", "Quantization", "Context Length", "Embedding Length", "Base Model Size", "KV Cache", "GPU RAM", "System RAM"}
+		subheader = table.Row{"", "Billions", "Units", "level", "bits", "", "", "", "", "", ""}
+		values    = len(header) - 1
 	)
 
+	if hosts {
+		header = append(table.Row{"Host"}, header...)
+		subheader = append(table.Row{""}, subheader...)
+	}
+
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(header, table.RowConfig{AutoMerge: true})
+	t.AppendHeader(subheader)
+
 	t.AppendSeparator()
 
+	inferred := false
 	for _, model := range models {
+		if model.Error != nil {
+			// the error spans the value columns
+			row := table.Row{model.Name}
+			for range values {
+				row = append(row, fmt.Sprintf("%+v", model.Error))
+			}
+			if hosts {
+				row = append(table.Row{model.Host}, row...)
+			}
+			t.AppendRow(row, table.RowConfig{AutoMerge: true})
+			continue
+		}
+
 		modelInfo := model.Model.ModelInfo
 		details := model.Model.Details
 		mem := tools.EstimateMemory(modelInfo.ParameterCount, modelInfo.ContextLength, details.QuantizationLevel)
+		inferred = inferred || len(modelInfo.Inferred) > 0
 
-		t.AppendRow([]interface{}{
+		row := table.Row{
 			model.Name,
-			text.AlignRight.Apply(tools.FormatParamCount(modelInfo.ParameterCount), 8),
-			text.AlignRight.Apply(fmt.Sprintf("%d", modelInfo.ParameterCount), 16),
+			text.AlignRight.Apply(tools.FormatParamCount(modelInfo.ParameterCount)+inferredMark(&modelInfo, ollama.FieldParameterCount), 8),
+			text.AlignRight.Apply(fmt.Sprintf("%d", modelInfo.ParameterCount)+inferredMark(&modelInfo, ollama.FieldParameterCount), 16),
 			text.AlignLeft.Apply(details.QuantizationLevel, 8),
 			text.AlignRight.Apply(fmt.Sprintf("%d", tools.QuantizationBits(tools.NormalizeQuantizationLevel(details.QuantizationLevel))), 6),
-			text.AlignRight.Apply(fmt.Sprintf("%d", modelInfo.ContextLength), 14),
-			text.AlignRight.Apply(fmt.Sprintf("%d", modelInfo.EmbeddingLength), 16),
+			text.AlignRight.Apply(fmt.Sprintf("%d", modelInfo.ContextLength)+inferredMark(&modelInfo, ollama.FieldContextLength), 14),
+			text.AlignRight.Apply(fmt.Sprintf("%d", modelInfo.EmbeddingLength)+inferredMark(&modelInfo, ollama.FieldEmbeddingLength), 16),
 			text.AlignRight.Apply(fmt.Sprintf("%.2f Gb", mem.BaseModelSize), 15),
 			text.AlignRight.Apply(fmt.Sprintf("%.2f Gb", mem.KVCacheSize), 10),
 			text.AlignRight.Apply(fmt.Sprintf("%.2f Gb", mem.GPURAM), 12),
 			text.AlignRight.Apply(fmt.Sprintf("%.2f Gb", mem.SystemRAM), 12),
-		})
+		}
+
+		if hosts {
+			row = append(table.Row{model.Host}, row...)
+		}
+		t.AppendRow(row)
+	}
+
+	if inferred {
+		t.AppendFooter(table.Row{"* inferred from the architectures database, not reported by the server"})
 	}
 
 	t.Render()
 }
 
-func ListTable(cfg *settings.Settings, model_name string) {
+func listModelsChart(cfg *settings.Settings, models []*ModelItem) {
+	entries := []tools.MemoryChartEntry{}
+
+	for _, model := range models {
+		if model.Error != nil {
+			fmt.Printf("%s: %+v\n", model.hostName(), model.Error)
+			continue
+		}
+
+		entries = append(entries, tools.MemoryChartEntry{
+			Name:              model.hostName(),
+			ParameterCount:    model.Model.ModelInfo.ParameterCount,
+			ContextLength:     model.Model.ModelInfo.ContextLength,
+			QuantizationLevel: model.Model.Details.QuantizationLevel,
+		})
+	}
+
+	tools.RenderChart(os.Stdout, tools.MemoryChart(entries, cfg.Hardware.VRAM, cfg.Hardware.RAM))
+}
+
+func ListTable(ctx context.Context, client ollama.Backend, model_name string) {
 	var (
 		err  error
 		tags = &ollama.Tags{}
@@ -102,7 +182,7 @@ func ListTable(cfg *settings.Settings, model_name string) {
 	)
 
 	if model_name == "" {
-		if tags, err = GetTags(cfg); err != nil {
+		if tags, err = GetTags(ctx, client); err != nil {
 			fmt.Printf("List getting tags: %v\n", err)
 			return
 		}
@@ -132,7 +212,7 @@ func ListTable(cfg *settings.Settings, model_name string) {
 	t.AppendSeparator()
 
 	for _, tag := range tags.Models {
-		model, err := GetModelInfo(cfg, tag.Name)
+		model, err := GetModelInfo(ctx, client, tag.Name)
 		if err != nil {
 			fmt.Printf("List getting model info: %+v\n", err)
 			return
@@ -0,0 +1,221 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/padiazg/ollama-tools/internals/tools"
+	"github.com/padiazg/ollama-tools/models/ollama"
+)
+
+const (
+	DefaultKeepAlive = "5m"
+	DefaultLoadWait  = 2 * time.Minute
+)
+
+// loadPollInterval is how often `/api/ps` is asked while waiting
+var loadPollInterval = 250 * time.Millisecond
+
+// LoadResult is a model as `/api/ps` reports it after loading, along with
+// the memory we estimated for it, if the quantization level is known
+type LoadResult struct {
+	Process    *ollama.ProcessModel
+	Estimation *ollama.MemoryEstimation
+}
+
+// Diff returns the estimated GPU RAM minus the actual loaded size, in GB
+func (r *LoadResult) Diff() float64 {
+	return loadedDiff(r.Estimation, r.Process)
+}
+
+// ParseKeepAlive reads a keep_alive the way the ollama cli does: a plain
+// number is seconds, anything else must be a duration like `10m` or `-1m`
+func ParseKeepAlive(keep_alive string) (any, error) {
+	if seconds, err := strconv.Atoi(keep_alive); err == nil {
+		return seconds, nil
+	}
+
+	if _, err := time.ParseDuration(keep_alive); err != nil {
+		return nil, fmt.Errorf("invalid keep alive %s, use seconds or a duration like 10m", keep_alive)
+	}
+
+	return keep_alive, nil
+}
+
+// Load loads a model with an empty generate request and waits until
+// `/api/ps` lists it, num_ctx is only sent when it's set
+func Load(ctx context.Context, client *ollama.Client, name string, keep_alive any, num_ctx int, wait time.Duration) (*LoadResult, error) {
+	name = withTag(name)
+
+	model, err := GetModelInfo(ctx, client, name)
+	if err != nil {
+		return nil, fmt.Errorf("getting model info: %+v", err)
+	}
+
+	req := &ollama.GenerateRequest{Model: name, KeepAlive: keep_alive}
+	if num_ctx > 0 {
+		req.Options = map[string]any{"num_ctx": num_ctx}
+	}
+
+	if _, err := client.Generate(ctx, req); err != nil {
+		return nil, fmt.Errorf("loading %s: %+v", name, err)
+	}
+
+	list, err := waitForPs(ctx, client, wait, func(list *ollama.ProcessList) bool {
+		return findProcess(list, name) != nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &LoadResult{Process: findProcess(list, name)}
+
+	// estimated with the context the model got, like ps does, older
+	// servers don't report it
+	context_length := result.Process.ContextLength
+	if context_length <= 0 {
+		context_length = num_ctx
+	}
+	if context_length <= 0 {
+		context_length = tools.DefaultNumCtx
+	}
+
+	if model.Details.QuantizationLevel != "" {
+		result.Estimation = tools.EstimateMemory(model.ModelInfo.ParameterCount, context_length, model.Details.QuantizationLevel)
+	}
+
+	return result, nil
+}
+
+// PrintLoad prints the memory a loaded model uses next to our estimate
+func PrintLoad(r *LoadResult) {
+	fmt.Printf("Loaded %s\n", r.Process.Name)
+	fmt.Printf("  VRAM: %.2f GB, Size: %.2f GB (%s)\n",
+		float64(r.Process.SizeVRAM)/ONE_GB, float64(r.Process.Size)/ONE_GB, processorSplit(r.Process.Size, r.Process.SizeVRAM))
+	if r.Estimation != nil {
+		fmt.Printf("  Estimated GPU RAM: %.2f GB (%+.2f GB)\n", r.Estimation.GPURAM, r.Diff())
+	}
+	fmt.Printf("  Until: %s\n", formatExpiresAt(r.Process.ExpiresAt, time.Now()))
+}
+
+// Unload unloads the models, or every loaded model with all, and waits
+// until `/api/ps` no longer lists them. It returns the models as they were
+// before unloading, names that aren't loaded are skipped
+func Unload(ctx context.Context, client *ollama.Client, names []string, all bool, wait time.Duration) ([]*RunningModel, error) {
+	running, err := RunningModels(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := []*RunningModel{}
+	if all {
+		targets = running
+	}
+
+	for _, name := range names {
+		found := false
+		for _, r := range running {
+			if r.Process.Name == withTag(name) || r.Process.Model == withTag(name) {
+				targets = append(targets, r)
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			fmt.Printf("%s is not loaded\n", name)
+		}
+	}
+
+	for _, r := range targets {
+		if _, err := client.Generate(ctx, &ollama.GenerateRequest{Model: r.Process.Name, KeepAlive: 0}); err != nil {
+			return nil, fmt.Errorf("unloading %s: %+v", r.Process.Name, err)
+		}
+	}
+
+	_, err = waitForPs(ctx, client, wait, func(list *ollama.ProcessList) bool {
+		for _, r := range targets {
+			if findProcess(list, r.Process.Name) != nil {
+				return false
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return targets, nil
+}
+
+// PrintUnload prints the VRAM freed by each model next to our estimate
+func PrintUnload(unloaded []*RunningModel) {
+	if len(unloaded) == 0 {
+		fmt.Println("No models unloaded")
+		return
+	}
+
+	var freed float64
+	for _, r := range unloaded {
+		vram := float64(r.Process.SizeVRAM) / ONE_GB
+		freed += vram
+
+		fmt.Printf("Unloaded %s, freed %.2f GB of VRAM", r.Process.Name, vram)
+		if r.Error == nil {
+			fmt.Printf(" (estimated %.2f GB, %+.2f GB)", r.Estimation.GPURAM, r.Diff())
+		}
+		fmt.Println()
+	}
+
+	fmt.Printf("Total VRAM freed: %.2f GB\n", freed)
+}
+
+// waitForPs asks `/api/ps` until done is true or wait is over
+func waitForPs(ctx context.Context, client *ollama.Client, wait time.Duration, done func(*ollama.ProcessList) bool) (*ollama.ProcessList, error) {
+	ctx, cancel := context.WithTimeout(ctx, wait)
+	defer cancel()
+
+	ticker := time.NewTicker(loadPollInterval)
+	defer ticker.Stop()
+
+	for {
+		list, err := client.Ps(ctx)
+		if err == nil && done(list) {
+			return list, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			if ctx.Err() == context.Canceled {
+				return nil, ctx.Err()
+			}
+			if err != nil {
+				return nil, fmt.Errorf("requesting running models: %+v", err)
+			}
+			return nil, fmt.Errorf("/api/ps didn't confirm the change after %s", wait)
+		case <-ticker.C:
+		}
+	}
+}
+
+// findProcess looks up a loaded model by name
+func findProcess(list *ollama.ProcessList, name string) *ollama.ProcessModel {
+	for i := range list.Models {
+		if list.Models[i].Name == name || list.Models[i].Model == name {
+			return &list.Models[i]
+		}
+	}
+
+	return nil
+}
+
+// withTag adds `:latest` to names without a tag, like the ollama cli
+func withTag(name string) string {
+	if !strings.Contains(name, ":") {
+		return name + ":latest"
+	}
+	return name
+}
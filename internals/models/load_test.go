@@ -0,0 +1,169 @@
+package models
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/padiazg/ollama-tools/internals/tools"
+	"github.com/padiazg/ollama-tools/models/ollama"
+	"github.com/padiazg/ollama-tools/models/ollama/ollamatest"
+	"github.com/stretchr/testify/assert"
+)
+
+// loadServer is the fake server where changes from generate requests only
+// show in `/api/ps` after a couple of polls, like a slow load would, or
+// never when it's stuck
+type loadServer struct {
+	fake     http.RoundTripper
+	mu       sync.Mutex
+	previous []byte
+	polls    int
+	stuck    bool
+	requests []*ollama.GenerateRequest
+}
+
+func newLoadServer() *loadServer {
+	return &loadServer{fake: ollamatest.New(nil).Transport()}
+}
+
+func (s *loadServer) roundTrip(r *http.Request) (*http.Response, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch r.URL.Path {
+	case ollama.ApiPathGenerate:
+		data, _ := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(bytes.NewReader(data))
+
+		req := &ollama.GenerateRequest{}
+		_ = json.Unmarshal(data, req)
+		s.requests = append(s.requests, req)
+
+		// ps keeps the list from before the change for a while
+		res, err := s.fake.RoundTrip(httptest.NewRequest(http.MethodGet, ollama.ApiPathPs, nil))
+		if err != nil {
+			return nil, err
+		}
+		s.previous, _ = io.ReadAll(res.Body)
+		s.polls = 0
+
+	case ollama.ApiPathPs:
+		if s.polls++; s.previous != nil && (s.polls <= 2 || s.stuck) {
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(s.previous))}, nil
+		}
+		s.previous = nil
+	}
+
+	return s.fake.RoundTrip(r)
+}
+
+func (s *loadServer) getClient() *ollama.Client {
+	return ollama.NewClient("http://ollama:11434", ollama.WithTransport(&DryRunTransport{RoundTripFn: s.roundTrip}))
+}
+
+func TestLoad(t *testing.T) {
+	loadPollInterval = time.Millisecond
+
+	var (
+		server = newLoadServer()
+		client = server.getClient()
+		phi4   = ollamatest.DefaultFixture().Models[0]
+	)
+
+	got, err := Load(context.Background(), client, "phi4", "10m", 8192, time.Second)
+	if !assert.NoError(t, err) || !assert.Len(t, server.requests, 1) {
+		return
+	}
+
+	assert.Equal(t, &ollama.GenerateRequest{
+		Model:     "phi4:latest",
+		Stream:    new(bool),
+		KeepAlive: "10m",
+		Options:   map[string]any{"num_ctx": float64(8192)},
+	}, server.requests[0])
+	assert.GreaterOrEqual(t, server.polls, 3, "waits until /api/ps lists the model")
+	assert.Equal(t, "phi4:latest", got.Process.Name)
+	assert.Equal(t, 8192, got.Process.ContextLength)
+	assert.Equal(t, tools.EstimateMemory(phi4.ParameterCount, 8192, phi4.QuantizationLevel), got.Estimation)
+	assert.InDelta(t, got.Estimation.GPURAM-float64(phi4.Size)/ONE_GB, got.Diff(), 1e-9, "compared with the size like ps")
+
+	_, err = Unload(context.Background(), client, []string{"phi4"}, false, time.Second)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	got, err = Load(context.Background(), client, "phi4", "10m", 0, time.Second)
+	if assert.NoError(t, err) {
+		assert.Equal(t, tools.EstimateMemory(phi4.ParameterCount, 4096, phi4.QuantizationLevel), got.Estimation, "estimated with the context /api/ps reports")
+	}
+
+	_, err = Load(context.Background(), client, "missing", "10m", 0, time.Second)
+	assert.ErrorContains(t, err, "getting model info")
+}
+
+func TestUnload(t *testing.T) {
+	loadPollInterval = time.Millisecond
+
+	var (
+		server = newLoadServer()
+		client = server.getClient()
+		llama  = ollamatest.DefaultFixture().Models[1]
+	)
+
+	got, err := Unload(context.Background(), client, []string{"phi4", "llama3.1"}, false, time.Second)
+	if !assert.NoError(t, err) || !assert.Len(t, got, 1) || !assert.Len(t, server.requests, 1) {
+		return
+	}
+
+	assert.Equal(t, float64(0), server.requests[0].KeepAlive)
+	assert.Equal(t, modelLlama3_1, got[0].Process.Name)
+	assert.Equal(t, llama.SizeVRAM, got[0].Process.SizeVRAM, "the model as it was before unloading")
+	assert.NotNil(t, got[0].Estimation)
+
+	list, err := client.Ps(context.Background())
+	if assert.NoError(t, err) {
+		assert.Empty(t, list.Models)
+	}
+
+	t.Run("timeout", func(t *testing.T) {
+		server := newLoadServer()
+		server.stuck = true
+
+		_, err := Unload(context.Background(), server.getClient(), nil, true, 20*time.Millisecond)
+		assert.ErrorContains(t, err, "/api/ps didn't confirm the change after 20ms")
+	})
+}
+
+func TestParseKeepAlive(t *testing.T) {
+	tests := []struct {
+		keep_alive string
+		want       any
+		wantErr    bool
+	}{
+		{keep_alive: "300", want: 300},
+		{keep_alive: "-1", want: -1},
+		{keep_alive: "10m", want: "10m"},
+		{keep_alive: "-1m", want: "-1m"},
+		{keep_alive: "forever", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.keep_alive, func(t *testing.T) {
+			got, err := ParseKeepAlive(tt.keep_alive)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
@@ -1,18 +1,33 @@
 package models
 
 import (
+	"context"
 	"fmt"
+	"slices"
 
 	"github.com/padiazg/ollama-tools/models/ollama"
-	"github.com/padiazg/ollama-tools/models/settings"
 )
 
 type ModelItem struct {
 	Name  string
+	Host  string
 	Model *ollama.Model
 	Error error
 }
 
+// hostName returns the model name, prefixed with its host when it has one
+func (m *ModelItem) hostName() string {
+	if m.Host == "" {
+		return m.Name
+	}
+	return m.Host + "/" + m.Name
+}
+
+// withHosts tells if the models come from several hosts
+func withHosts(models []*ModelItem) bool {
+	return slices.ContainsFunc(models, func(m *ModelItem) bool { return m.Host != "" })
+}
+
 type pair struct {
 	name  string
 	model *ollama.Model
@@ -20,14 +35,15 @@ type pair struct {
 }
 
 type nextData struct {
+	ctx        context.Context
 	model_name string
-	cfg        *settings.Settings
+	client     ollama.Backend
 }
 
 type nextFn func() nextData
 
-func ModelsInfoList(cfg *settings.Settings, model_name string) ([]*ModelItem, error) {
-	next, err := modelsInfoGenerator(cfg, model_name)
+func ModelsInfoList(ctx context.Context, client ollama.Backend, model_name string) ([]*ModelItem, error) {
+	next, err := modelsInfoGenerator(ctx, client, model_name)
 	if err != nil {
 		return nil, err
 	}
@@ -35,7 +51,7 @@ func ModelsInfoList(cfg *settings.Settings, model_name string) ([]*ModelItem, er
 	return modelsInfoList(next), nil
 }
 
-func modelsInfoGenerator(cfg *settings.Settings, model_name string) (func() nextData, error) {
+func modelsInfoGenerator(ctx context.Context, client ollama.Backend, model_name string) (func() nextData, error) {
 	var (
 		tags  *ollama.Tags
 		err   error
@@ -43,7 +59,7 @@ func modelsInfoGenerator(cfg *settings.Settings, model_name string) (func() next
 	)
 
 	if model_name == "" {
-		if tags, err = GetTags(cfg); err != nil {
+		if tags, err = GetTags(ctx, client); err != nil {
 			return nil, fmt.Errorf("getting tags: %v", err)
 		}
 	} else {
@@ -57,8 +73,9 @@ func modelsInfoGenerator(cfg *settings.Settings, model_name string) (func() next
 			return nextData{model_name: ""}
 		}
 		data := nextData{
+			ctx:        ctx,
 			model_name: tags.Models[index].Name,
-			cfg:        cfg,
+			client:     client,
 		}
 		index++
 		return data
@@ -104,7 +121,7 @@ func modelsInfoFetcher(pending <-chan nextData) chan pair {
 				break
 			}
 
-			model, err = GetModelInfo(data.cfg, data.model_name)
+			model, err = GetModelInfo(data.ctx, data.client, data.model_name)
 			fetched <- pair{
 				name:  data.model_name,
 				model: model,
@@ -1,15 +1,18 @@
 package models
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
+	"slices"
 	"strings"
 	"testing"
 
+	"github.com/padiazg/ollama-tools/internals/capture"
 	"github.com/padiazg/ollama-tools/models/ollama"
-	"github.com/padiazg/ollama-tools/models/settings"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -28,9 +31,10 @@ func (t tags) getBody() string {
 		return ""
 	}
 
+	// sorted by name, so the models come in the same order on every run
 	tmp := ollama.Tags{}
-	for _, model := range t {
-		tmp.Models = append(tmp.Models, *model)
+	for _, name := range slices.Sorted(maps.Keys(t)) {
+		tmp.Models = append(tmp.Models, *t[name])
 	}
 
 	body, _ := json.Marshal(tmp)
@@ -38,8 +42,8 @@ func (t tags) getBody() string {
 }
 
 func (t tags) getModels() (models []string) {
-	for _, tag := range t {
-		models = append(models, tag.Name)
+	for _, name := range slices.Sorted(maps.Keys(t)) {
+		models = append(models, t[name].Name)
 	}
 
 	return
@@ -55,12 +59,12 @@ func (t tags) filter(list []string) tags {
 	return ret
 }
 
-func (t tags) getConfig(wantError error) *settings.Settings {
+func (t tags) getClient(wantError error) *ollama.Client {
 	body := t.getBody()
-	return &settings.Settings{
-		OllamaUrl: "http://ollama:11434",
-		Transport: &DryRunTransport{RoundTripFn: generalRoundTripper(body, wantError)},
-	}
+	return ollama.NewClient(
+		"http://ollama:11434",
+		ollama.WithTransport(&DryRunTransport{RoundTripFn: generalRoundTripper(body, wantError)}),
+	)
 }
 
 func (m modelsInfo) getModels(list []string) []*ModelItem {
@@ -157,8 +161,9 @@ var (
 	modelsList = modelsInfo{
 		modelPhi4: {
 			name:       modelPhi4,
-			normalized: `{"license":"Microsoft...SOFTWARE.","modelfile":"# Modelfile ...","parameters":"stop                           \"\u003c|im_start|\u003e\"\nstop                           \"\u003c|im_end|\u003e\"\nstop                           \"\u003c|im_sep|\u003e\"","template":"{{- range $i, $_ := .Messages }}\n{{- $last := eq (len (slice $.Messages $i)) 1 -}}\n\u003c|im_start|\u003e{{ .Role }}\u003c|im_sep|\u003e\n{{ .Content }}{{ if not $last }}\u003c|im_end|\u003e\n{{ end }}\n{{- if and (ne .Role \"assistant\") $last }}\u003c|im_end|\u003e\n\u003c|im_start|\u003eassistant\u003c|im_sep|\u003e\n{{ end }}\n{{- end }}","details":{"parent_model":"","format":"gguf","family":"phi3","families":["phi3"],"parameter_size":"14.7B","quantization_level":"Q4_K_M"},"model_info":{"general.architecture":"phi3","general.basename":"phi","general.file_type":15,"general.languages":["en"],"general.license":"mit","general.license.link":"https://huggingface.co/microsoft/phi-4/resolve/main/LICENSE","general.organization":"Microsoft","general.parameter_count":14659507200,"general.quantization_version":2,"general.size_label":"15B","general.tags":["phi","nlp","math","code","chat","conversational","text-generation"],"general.type":"model","general.version":"4","phi3.attention.head_count":40,"phi3.attention.head_count_kv":10,"phi3.attention.layer_norm_rms_epsilon":0.00001,"phi3.attention.sliding_window":131072,"phi3.block_count":40,"model.context_length":16384,"model.embedding_length":5120,"phi3.feed_forward_length":17920,"phi3.rope.dimension_count":128,"phi3.rope.freq_base":250000,"phi3.rope.scaling.original_context_length":16384,"tokenizer.ggml.bos_token_id":100257,"tokenizer.ggml.eos_token_id":100257,"tokenizer.ggml.merges":null,"tokenizer.ggml.model":"gpt2","tokenizer.ggml.padding_token_id":100257,"tokenizer.ggml.pre":"dbrx","tokenizer.ggml.token_type":null,"tokenizer.ggml.tokens":null},"modified_at":"2025-01-14T17:21:17.785607967-03:00"}`,
+			normalized: `{"license":"Microsoft...SOFTWARE.","modelfile":"# Modelfile ...","parameters":"stop                           \"\u003c|im_start|\u003e\"\nstop                           \"\u003c|im_end|\u003e\"\nstop                           \"\u003c|im_sep|\u003e\"","template":"{{- range $i, $_ := .Messages }}\n{{- $last := eq (len (slice $.Messages $i)) 1 -}}\n\u003c|im_start|\u003e{{ .Role }}\u003c|im_sep|\u003e\n{{ .Content }}{{ if not $last }}\u003c|im_end|\u003e\n{{ end }}\n{{- if and (ne .Role \"assistant\") $last }}\u003c|im_end|\u003e\n\u003c|im_start|\u003eassistant\u003c|im_sep|\u003e\n{{ end }}\n{{- end }}","details":{"parent_model":"","format":"gguf","family":"phi3","families":["phi3"],"parameter_size":"14.7B","quantization_level":"Q4_K_M"},"model_info":{"general.architecture":"phi3","general.basename":"phi","general.file_type":15,"general.languages":["en"],"general.license":"mit","general.license.link":"https://huggingface.co/microsoft/phi-4/resolve/main/LICENSE","general.organization":"Microsoft","general.parameter_count":14659507200,"general.quantization_version":2,"general.size_label":"15B","general.tags":["phi","nlp","math","code","chat","conversational","text-generation"],"general.type":"model","general.version":"4","model.attention.head_count":40,"model.attention.head_count_kv":10,"phi3.attention.layer_norm_rms_epsilon":0.00001,"phi3.attention.sliding_window":131072,"model.block_count":40,"model.context_length":16384,"model.embedding_length":5120,"model.feed_forward_length":17920,"phi3.rope.dimension_count":128,"phi3.rope.freq_base":250000,"phi3.rope.scaling.original_context_length":16384,"tokenizer.ggml.bos_token_id":100257,"tokenizer.ggml.eos_token_id":100257,"tokenizer.ggml.merges":null,"tokenizer.ggml.model":"gpt2","tokenizer.ggml.padding_token_id":100257,"tokenizer.ggml.pre":"dbrx","tokenizer.ggml.token_type":null,"tokenizer.ggml.tokens":null},"modified_at":"2025-01-14T17:21:17.785607967-03:00"}`,
 			model: &ollama.Model{
+				Modelfile: "# Modelfile ...",
 				Details: ollama.ModelDetails{
 					ParentModel:       "",
 					Format:            "gguf",
@@ -168,17 +173,22 @@ var (
 					QuantizationLevel: "Q4_K_M",
 				},
 				ModelInfo: ollama.ModelInfo{
-					Type:            "model",
-					ParameterCount:  14659507200,
-					ContextLength:   16384,
-					EmbeddingLength: 5120,
+					Type:              "model",
+					ParameterCount:    14659507200,
+					ContextLength:     16384,
+					EmbeddingLength:   5120,
+					BlockCount:        40,
+					FeedForwardLength: 17920,
+					HeadCount:         40,
+					HeadCountKV:       10,
 				},
 			},
 		},
 		modelLlama3_1: {
 			name:       modelLlama3_1,
-			normalized: `{"license":"LLAMA 3.1 ...","modelfile":"# Modelfile ...","parameters":"stop                           \"\u003c|start_header_id|\u003e\"\nstop                           \"\u003c|end_header_id|\u003e\"\nstop                           \"\u003c|eot_id|\u003e\"","template":"{{- if or .System .Tools }}\u003c|start_header_id|\u003esystem\u003c|end_header_id|\u003e\n{{- if .System }}\n\n{{ .System }}\n{{- end }}\n{{- if .Tools }}\n\nCutting Knowledge Date: December 2023\n\nWhen you receive a tool call response, use the output to format an answer to the orginal user question.\n\nYou are a helpful assistant with tool calling capabilities.\n{{- end }}\u003c|eot_id|\u003e\n{{- end }}\n{{- range $i, $_ := .Messages }}\n{{- $last := eq (len (slice $.Messages $i)) 1 }}\n{{- if eq .Role \"user\" }}\u003c|start_header_id|\u003euser\u003c|end_header_id|\u003e\n{{- if and $.Tools $last }}\n\nGiven the following functions, please respond with a JSON for a function call with its proper arguments that best answers the given prompt.\n\nRespond in the format {\"name\": function name, \"parameters\": dictionary of argument name and its value}. Do not use variables.\n\n{{ range $.Tools }}\n{{- . }}\n{{ end }}\nQuestion: {{ .Content }}\u003c|eot_id|\u003e\n{{- else }}\n\n{{ .Content }}\u003c|eot_id|\u003e\n{{- end }}{{ if $last }}\u003c|start_header_id|\u003eassistant\u003c|end_header_id|\u003e\n\n{{ end }}\n{{- else if eq .Role \"assistant\" }}\u003c|start_header_id|\u003eassistant\u003c|end_header_id|\u003e\n{{- if .ToolCalls }}\n{{ range .ToolCalls }}\n{\"name\": \"{{ .Function.Name }}\", \"parameters\": {{ .Function.Arguments }}}{{ end }}\n{{- else }}\n\n{{ .Content }}\n{{- end }}{{ if not $last }}\u003c|eot_id|\u003e{{ end }}\n{{- else if eq .Role \"tool\" }}\u003c|start_header_id|\u003eipython\u003c|end_header_id|\u003e\n\n{{ .Content }}\u003c|eot_id|\u003e{{ if $last }}\u003c|start_header_id|\u003eassistant\u003c|end_header_id|\u003e\n\n{{ end }}\n{{- end }}\n{{- end }}","details":{"parent_model":"","format":"gguf","family":"llama","families":["llama"],"parameter_size":"8.0B","quantization_level":"Q4_K_M"},"model_info":{"general.architecture":"llama","general.basename":"Meta-Llama-3.1","general.file_type":15,"general.finetune":"Instruct","general.languages":["en","de","fr","it","pt","hi","es","th"],"general.license":"llama3.1","general.parameter_count":8030261312,"general.quantization_version":2,"general.size_label":"8B","general.tags":["facebook","meta","pytorch","llama","llama-3","text-generation"],"general.type":"model","llama.attention.head_count":32,"llama.attention.head_count_kv":8,"llama.attention.layer_norm_rms_epsilon":0.00001,"llama.block_count":32,"model.context_length":131072,"model.embedding_length":4096,"llama.feed_forward_length":14336,"llama.rope.dimension_count":128,"llama.rope.freq_base":500000,"llama.vocab_size":128256,"tokenizer.ggml.bos_token_id":128000,"tokenizer.ggml.eos_token_id":128009,"tokenizer.ggml.merges":null,"tokenizer.ggml.model":"gpt2","tokenizer.ggml.pre":"llama-bpe","tokenizer.ggml.token_type":null,"tokenizer.ggml.tokens":null},"modified_at":"2025-02-03T19:22:17.054410969-03:00"}`,
+			normalized: `{"license":"LLAMA 3.1 ...","modelfile":"# Modelfile ...","parameters":"stop                           \"\u003c|start_header_id|\u003e\"\nstop                           \"\u003c|end_header_id|\u003e\"\nstop                           \"\u003c|eot_id|\u003e\"","template":"{{- if or .System .Tools }}\u003c|start_header_id|\u003esystem\u003c|end_header_id|\u003e\n{{- if .System }}\n\n{{ .System }}\n{{- end }}\n{{- if .Tools }}\n\nCutting Knowledge Date: December 2023\n\nWhen you receive a tool call response, use the output to format an answer to the orginal user question.\n\nYou are a helpful assistant with tool calling capabilities.\n{{- end }}\u003c|eot_id|\u003e\n{{- end }}\n{{- range $i, $_ := .Messages }}\n{{- $last := eq (len (slice $.Messages $i)) 1 }}\n{{- if eq .Role \"user\" }}\u003c|start_header_id|\u003euser\u003c|end_header_id|\u003e\n{{- if and $.Tools $last }}\n\nGiven the following functions, please respond with a JSON for a function call with its proper arguments that best answers the given prompt.\n\nRespond in the format {\"name\": function name, \"parameters\": dictionary of argument name and its value}. Do not use variables.\n\n{{ range $.Tools }}\n{{- . }}\n{{ end }}\nQuestion: {{ .Content }}\u003c|eot_id|\u003e\n{{- else }}\n\n{{ .Content }}\u003c|eot_id|\u003e\n{{- end }}{{ if $last }}\u003c|start_header_id|\u003eassistant\u003c|end_header_id|\u003e\n\n{{ end }}\n{{- else if eq .Role \"assistant\" }}\u003c|start_header_id|\u003eassistant\u003c|end_header_id|\u003e\n{{- if .ToolCalls }}\n{{ range .ToolCalls }}\n{\"name\": \"{{ .Function.Name }}\", \"parameters\": {{ .Function.Arguments }}}{{ end }}\n{{- else }}\n\n{{ .Content }}\n{{- end }}{{ if not $last }}\u003c|eot_id|\u003e{{ end }}\n{{- else if eq .Role \"tool\" }}\u003c|start_header_id|\u003eipython\u003c|end_header_id|\u003e\n\n{{ .Content }}\u003c|eot_id|\u003e{{ if $last }}\u003c|start_header_id|\u003eassistant\u003c|end_header_id|\u003e\n\n{{ end }}\n{{- end }}\n{{- end }}","details":{"parent_model":"","format":"gguf","family":"llama","families":["llama"],"parameter_size":"8.0B","quantization_level":"Q4_K_M"},"model_info":{"general.architecture":"llama","general.basename":"Meta-Llama-3.1","general.file_type":15,"general.finetune":"Instruct","general.languages":["en","de","fr","it","pt","hi","es","th"],"general.license":"llama3.1","general.parameter_count":8030261312,"general.quantization_version":2,"general.size_label":"8B","general.tags":["facebook","meta","pytorch","llama","llama-3","text-generation"],"general.type":"model","model.attention.head_count":32,"model.attention.head_count_kv":8,"llama.attention.layer_norm_rms_epsilon":0.00001,"model.block_count":32,"model.context_length":131072,"model.embedding_length":4096,"model.feed_forward_length":14336,"llama.rope.dimension_count":128,"llama.rope.freq_base":500000,"llama.vocab_size":128256,"tokenizer.ggml.bos_token_id":128000,"tokenizer.ggml.eos_token_id":128009,"tokenizer.ggml.merges":null,"tokenizer.ggml.model":"gpt2","tokenizer.ggml.pre":"llama-bpe","tokenizer.ggml.token_type":null,"tokenizer.ggml.tokens":null},"modified_at":"2025-02-03T19:22:17.054410969-03:00"}`,
 			model: &ollama.Model{
+				Modelfile: "# Modelfile ...",
 				Details: ollama.ModelDetails{
 					ParentModel:       "",
 					Format:            "gguf",
@@ -188,10 +198,14 @@ var (
 					QuantizationLevel: "Q4_K_M",
 				},
 				ModelInfo: ollama.ModelInfo{
-					Type:            "model",
-					ParameterCount:  8030261312,
-					ContextLength:   131072,
-					EmbeddingLength: 4096,
+					Type:              "model",
+					ParameterCount:    8030261312,
+					ContextLength:     131072,
+					EmbeddingLength:   4096,
+					BlockCount:        32,
+					FeedForwardLength: 14336,
+					HeadCount:         32,
+					HeadCountKV:       8,
 				},
 			},
 		},
@@ -246,9 +260,9 @@ var (
 			)
 
 			switch r.URL.Path {
-			case apiPathTags:
+			case ollama.ApiPathTags:
 				return tagsRoundTripper(body, wantError)(r)
-			case apiPathShow:
+			case ollama.ApiPathShow:
 				return modelListRoundTripper(wantError)(r)
 			}
 
@@ -256,11 +270,11 @@ var (
 		}
 	}
 
-	getConfigModelsList = func(wantError error) *settings.Settings {
-		return &settings.Settings{
-			OllamaUrl: "http://ollama:11434",
-			Transport: &DryRunTransport{RoundTripFn: modelListRoundTripper(wantError)},
-		}
+	getClientModelsList = func(wantError error) *ollama.Client {
+		return ollama.NewClient(
+			"http://ollama:11434",
+			ollama.WithTransport(&DryRunTransport{RoundTripFn: modelListRoundTripper(wantError)}),
+		)
 	}
 )
 
@@ -304,14 +318,14 @@ func TestModelsInfoList(t *testing.T) {
 				t.Run(tt.name, func(t *testing.T) {
 					var (
 						tags           tags
-						cfg            *settings.Settings
+						client         *ollama.Client
 						want           []*ModelItem
 						wantError      error
 						wantModelsList []string
 					)
 
 					if tt.model_name == "" {
-						wantModelsList = tt.models
+						wantModelsList = slices.Sorted(slices.Values(tt.models))
 					} else {
 						wantModelsList = []string{tt.model_name}
 					}
@@ -323,8 +337,8 @@ func TestModelsInfoList(t *testing.T) {
 						wantError = fmt.Errorf(tt.wantErrorMsg)
 					}
 
-					cfg = tags.getConfig(wantError)
-					got, err := ModelsInfoList(cfg, tt.model_name)
+					client = tags.getClient(wantError)
+					got, err := ModelsInfoList(context.Background(), client, tt.model_name)
 
 					if wantError != nil {
 						assert.ErrorContains(t, err, tt.wantErrorMsg)
@@ -400,7 +414,7 @@ func Test_modelsInfoGenerator(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			var (
 				tags      tags
-				cfg       *settings.Settings
+				client    *ollama.Client
 				want      []string
 				wantError error
 			)
@@ -416,8 +430,8 @@ func Test_modelsInfoGenerator(t *testing.T) {
 				wantError = fmt.Errorf(tt.wantErrorMsg)
 			}
 
-			cfg = tags.getConfig(wantError)
-			next, err := modelsInfoGenerator(cfg, tt.model_name)
+			client = tags.getClient(wantError)
+			next, err := modelsInfoGenerator(context.Background(), client, tt.model_name)
 			if wantError != nil {
 				assert.ErrorContains(t, err, tt.wantErrorMsg)
 			} else {
@@ -443,8 +457,9 @@ func Test_modelsInfoList(t *testing.T) {
 				}
 
 				data := nextData{
+					ctx:        context.Background(),
 					model_name: modelItemList[index].Name,
-					cfg:        getConfigModelsList(nil),
+					client:     getClientModelsList(nil),
 				}
 				index++
 				return data
@@ -589,16 +604,18 @@ func Test_modelsInfoFetcher(t *testing.T) {
 			{
 				name: "success",
 				data: nextData{
+					ctx:        context.Background(),
 					model_name: modelPhi4,
-					cfg:        getConfigModelsList(nil),
+					client:     getClientModelsList(nil),
 				},
 				check: checkModel(modelsList[modelPhi4].model),
 			},
 			{
 				name: "error",
 				data: nextData{
+					ctx:        context.Background(),
 					model_name: modelPhi4,
-					cfg:        getConfigModelsList(fmt.Errorf("test-GetModelInfo-error")),
+					client:     getClientModelsList(fmt.Errorf("test-GetModelInfo-error")),
 				},
 				check: checkError("test-GetModelInfo-error"),
 			},
@@ -664,3 +681,48 @@ func Test_modelsInfoFill(t *testing.T) {
 		})
 	}
 }
+
+func TestModelsInfoList_replay(t *testing.T) {
+	replayer, err := capture.NewReplayer("testdata/models_list.jsonl")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	client := ollama.NewClient("http://ollama:11434", ollama.WithTransport(replayer))
+	defer client.Close()
+
+	got, err := ModelsInfoList(context.Background(), client, "")
+	if !assert.NoError(t, err) || !assert.Len(t, got, 5) {
+		return
+	}
+
+	by_name := map[string]*ModelItem{}
+	for _, item := range got {
+		assert.NoError(t, item.Error, item.Name)
+		by_name[item.Name] = item
+	}
+
+	if phi4 := by_name["phi4:14b"]; assert.NotNil(t, phi4) {
+		assert.Equal(t, "Q4_K_M", phi4.Model.Details.QuantizationLevel)
+		assert.Equal(t, int64(14659507200), phi4.Model.ModelInfo.ParameterCount)
+		assert.Equal(t, 16384, phi4.Model.ModelInfo.ContextLength)
+		assert.Equal(t, 40, phi4.Model.ModelInfo.BlockCount)
+	}
+
+	if nomic := by_name["nomic-embed-text:latest"]; assert.NotNil(t, nomic) {
+		assert.Equal(t, "F16", nomic.Model.Details.QuantizationLevel)
+		assert.Equal(t, 2048, nomic.Model.ModelInfo.ContextLength)
+		assert.Equal(t, 768, nomic.Model.ModelInfo.EmbeddingLength)
+	}
+}
+
+func Test_listModels_failed(t *testing.T) {
+	items := []*ModelItem{
+		{Name: modelPhi4, Model: modelsList[modelPhi4].model},
+		{Name: "nosuch:model", Error: fmt.Errorf("model 'nosuch:model' not found")},
+		{Name: "nosuch:model", Host: "gpu-box", Error: fmt.Errorf("connection refused")},
+	}
+
+	assert.NotPanics(t, func() { listModelsDetail(items) })
+	assert.NotPanics(t, func() { listModelsTable(items) })
+}
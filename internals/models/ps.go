@@ -0,0 +1,203 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/padiazg/ollama-tools/internals/tools"
+	"github.com/padiazg/ollama-tools/models/ollama"
+)
+
+// RunningModel is a loaded model along with the memory we estimate for it
+type RunningModel struct {
+	Host       string
+	Process    *ollama.ProcessModel
+	Model      *ollama.Model
+	Estimation *ollama.MemoryEstimation
+	Error      error
+}
+
+// ContextLength returns the context the model was loaded with, older
+// servers don't report it so the model maximum is used
+func (r *RunningModel) ContextLength() int {
+	if r.Process.ContextLength > 0 || r.Model == nil {
+		return r.Process.ContextLength
+	}
+	return r.Model.ModelInfo.ContextLength
+}
+
+// Diff returns the estimated GPU RAM minus the actual loaded size, in GB
+func (r *RunningModel) Diff() float64 {
+	return loadedDiff(r.Estimation, r.Process)
+}
+
+// loadedDiff compares the estimate with the size `/api/ps` reports, which
+// is the whole model whether it's split with the CPU or not. load, unload
+// and ps all use it so they agree
+func loadedDiff(estimation *ollama.MemoryEstimation, process *ollama.ProcessModel) float64 {
+	return estimation.GPURAM - float64(process.Size)/ONE_GB
+}
+
+// RunningModels lists the loaded models and estimates their memory with the
+// context length they were loaded with
+func RunningModels(ctx context.Context, client *ollama.Client) ([]*RunningModel, error) {
+	list, err := client.Ps(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("requesting running models: %+v", err)
+	}
+
+	running := make([]*RunningModel, 0, len(list.Models))
+	for i := range list.Models {
+		r := &RunningModel{Process: &list.Models[i]}
+		running = append(running, r)
+
+		if r.Model, r.Error = GetModelInfo(ctx, client, r.Process.Name); r.Error != nil {
+			continue
+		}
+
+		quantization_level := r.Model.Details.QuantizationLevel
+		if quantization_level == "" {
+			quantization_level = r.Process.Details.QuantizationLevel
+		}
+
+		if quantization_level == "" {
+			r.Error = fmt.Errorf("unknown quantization level")
+			continue
+		}
+
+		r.Estimation = tools.EstimateMemory(r.Model.ModelInfo.ParameterCount, r.ContextLength(), quantization_level)
+	}
+
+	return running, nil
+}
+
+// Ps prints the models loaded in memory
+func Ps(ctx context.Context, client *ollama.Client, table bool) {
+	running, err := RunningModels(ctx, client)
+	if err != nil {
+		fmt.Printf("listing running models: %+v\n", err)
+		return
+	}
+
+	if len(running) == 0 {
+		fmt.Println("No models loaded")
+		return
+	}
+
+	if table {
+		psTable(running)
+	} else {
+		psDetail(running)
+	}
+
+	// older servers don't report the context a model was loaded with
+	if err := client.Require(ctx, ollama.FeaturePsContextLength); err != nil {
+		fmt.Printf("Note: %+v, the context length shown is the model maximum\n", err)
+	}
+}
+
+func psDetail(running []*RunningModel) {
+	fmt.Println("Running models:")
+	fmt.Println("----------------------------------------------------")
+	for _, r := range running {
+		fmt.Printf("Model: %s\n", r.Process.Name)
+		if r.Host != "" {
+			fmt.Printf("  Host: %s\n", r.Host)
+		}
+		fmt.Printf("  Size: %.2f GB\n", float64(r.Process.Size)/ONE_GB)
+		fmt.Printf("  VRAM: %.2f GB\n", float64(r.Process.SizeVRAM)/ONE_GB)
+		fmt.Printf("  Processor: %s\n", processorSplit(r.Process.Size, r.Process.SizeVRAM))
+		fmt.Printf("  Context Length: %d tokens\n", r.ContextLength())
+		fmt.Printf("  Until: %s\n", formatExpiresAt(r.Process.ExpiresAt, time.Now()))
+
+		if r.Error != nil {
+			fmt.Printf("  Estimation: %+v\n", r.Error)
+		} else {
+			fmt.Printf("  Estimated GPU RAM: %.2f GB (%+.2f GB)\n", r.Estimation.GPURAM, r.Diff())
+		}
+		fmt.Println("")
+	}
+}
+
+func psTable(running []*RunningModel) {
+	var (
+		t         = table.NewWriter()
+		hosts     = slices.ContainsFunc(running, func(r *RunningModel) bool { return r.Host != "" })
+		header    = table.Row{"Model", "Size", "VRAM", "Processor", "Context Length", "Until", "Estimated", "Estimated"}
+		subheader = table.Row{"", "", "", "", "", "", "GPU RAM", "Diff"}
+	)
+
+	if hosts {
+		header = append(table.Row{"Host"}, header...)
+		subheader = append(table.Row{""}, subheader...)
+	}
+
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(header, table.RowConfig{AutoMerge: true})
+	t.AppendHeader(subheader)
+
+	t.AppendSeparator()
+
+	now := time.Now()
+	for _, r := range running {
+		var estimated, diff string
+		if r.Error != nil {
+			estimated, diff = "-", "-"
+		} else {
+			estimated = fmt.Sprintf("%.2f Gb", r.Estimation.GPURAM)
+			diff = fmt.Sprintf("%+.2f Gb", r.Diff())
+		}
+
+		row := table.Row{
+			r.Process.Name,
+			text.AlignRight.Apply(fmt.Sprintf("%.2f Gb", float64(r.Process.Size)/ONE_GB), 10),
+			text.AlignRight.Apply(fmt.Sprintf("%.2f Gb", float64(r.Process.SizeVRAM)/ONE_GB), 10),
+			processorSplit(r.Process.Size, r.Process.SizeVRAM),
+			text.AlignRight.Apply(fmt.Sprintf("%d", r.ContextLength()), 14),
+			formatExpiresAt(r.Process.ExpiresAt, now),
+			text.AlignRight.Apply(estimated, 10),
+			text.AlignRight.Apply(diff, 10),
+		}
+
+		if hosts {
+			row = append(table.Row{r.Host}, row...)
+		}
+		t.AppendRow(row)
+	}
+
+	t.Render()
+}
+
+// processorSplit returns the CPU/GPU share of a loaded model the way
+// `ollama ps` prints it
+func processorSplit(size int64, size_vram int64) string {
+	switch {
+	case size_vram == 0:
+		return "100% CPU"
+	case size_vram >= size:
+		return "100% GPU"
+	}
+
+	cpu := float64(size-size_vram) / float64(size) * 100
+	return fmt.Sprintf("%.0f%%/%.0f%% CPU/GPU", cpu, 100-cpu)
+}
+
+// formatExpiresAt returns how long until the model is unloaded, models
+// loaded with a negative keep_alive never expire
+func formatExpiresAt(expires_at time.Time, now time.Time) string {
+	switch {
+	case expires_at.IsZero():
+		return "-"
+	case expires_at.Year() >= now.Year()+100:
+		return "Forever"
+	case expires_at.Before(now):
+		return "Stopping..."
+	}
+
+	return "in " + expires_at.Sub(now).Round(time.Second).String()
+}
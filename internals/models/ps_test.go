@@ -0,0 +1,63 @@
+package models
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/padiazg/ollama-tools/internals/tools"
+	"github.com/padiazg/ollama-tools/models/ollama"
+	"github.com/padiazg/ollama-tools/models/ollama/ollamatest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunningModels(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		// phi4 loaded next to llama3.1, which has no metadata
+		fixture := ollamatest.DefaultFixture()
+		fixture.Models[0].Loaded = true
+		fixture.Errors = []*ollamatest.Fault{{Path: ollama.ApiPathShow, Model: modelLlama3_1, Status: http.StatusNotFound, Message: "model not found"}}
+
+		client := ollama.NewClient("http://ollama:11434", ollama.WithTransport(ollamatest.New(fixture).Transport()))
+		defer client.Close()
+
+		got, err := RunningModels(context.Background(), client)
+		if !assert.NoError(t, err) || !assert.Len(t, got, 2) {
+			return
+		}
+
+		phi4 := fixture.Models[0]
+		assert.Equal(t, 4096, got[0].ContextLength())
+		assert.NoError(t, got[0].Error)
+		assert.Equal(t, tools.EstimateMemory(phi4.ParameterCount, 4096, phi4.QuantizationLevel), got[0].Estimation)
+		assert.InDelta(t, got[0].Estimation.GPURAM-float64(phi4.Size)/ONE_GB, got[0].Diff(), 1e-9)
+
+		assert.Error(t, got[1].Error)
+		assert.Nil(t, got[1].Estimation)
+	})
+
+	t.Run("request-error", func(t *testing.T) {
+		fixture := &ollamatest.Fixture{Errors: []*ollamatest.Fault{{Path: ollama.ApiPathPs, Message: "test-ps-error"}}}
+		client := ollama.NewClient("http://ollama:11434", ollama.WithTransport(ollamatest.New(fixture).Transport()))
+		defer client.Close()
+
+		_, err := RunningModels(context.Background(), client)
+		assert.ErrorContains(t, err, "test-ps-error")
+	})
+}
+
+func Test_processorSplit(t *testing.T) {
+	assert.Equal(t, "100% CPU", processorSplit(100, 0))
+	assert.Equal(t, "100% GPU", processorSplit(100, 100))
+	assert.Equal(t, "25%/75% CPU/GPU", processorSplit(100, 75))
+}
+
+func Test_formatExpiresAt(t *testing.T) {
+	now := time.Date(2025, 3, 12, 10, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, "-", formatExpiresAt(time.Time{}, now))
+	assert.Equal(t, "in 4m5s", formatExpiresAt(now.Add(4*time.Minute+5*time.Second), now))
+	assert.Equal(t, "Stopping...", formatExpiresAt(now.Add(-time.Second), now))
+	assert.Equal(t, "Forever", formatExpiresAt(now.AddDate(300, 0, 0), now))
+}
@@ -0,0 +1,300 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/progress"
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/padiazg/ollama-tools/internals/tools"
+	"github.com/padiazg/ollama-tools/models/ollama"
+	"github.com/padiazg/ollama-tools/models/settings"
+)
+
+const (
+	DefaultPullConcurrency = 2
+	// defaultTagQuantization is what the registry serves for tags without
+	// an explicit quantization
+	defaultTagQuantization = "Q4_K_M"
+)
+
+var (
+	tagSizeRe  = regexp.MustCompile(`^(\d+x)?\d+(\.\d+)?[kmbt]$`)
+	tagQuantRe = regexp.MustCompile(`^(q\d.*|iq\d.*|f16|f32|bf16|fp16)$`)
+)
+
+// PullEstimate is the memory a model is expected to need once pulled
+type PullEstimate struct {
+	Name              string
+	Source            string
+	ParameterCount    int64
+	QuantizationLevel string
+	Estimation        *ollama.MemoryEstimation
+}
+
+// PullResult is the outcome of pulling a model
+type PullResult struct {
+	Name     string
+	Status   string
+	Size     int64
+	Duration time.Duration
+	Error    error
+}
+
+// Pull checks the models against the hardware profile and pulls them,
+// at most concurrency at a time, printing a summary at the end
+func Pull(ctx context.Context, client *ollama.Client, hw settings.Hardware, names []string, concurrency int, insecure bool) {
+	for _, name := range names {
+		printPullEstimate(PullPreflight(ctx, client, name), hw)
+	}
+	fmt.Println("")
+
+	results := PullModels(ctx, client, names, concurrency, insecure, os.Stdout)
+	fmt.Println("")
+	pullSummary(results)
+}
+
+// PullPreflight estimates the memory a model needs. Installed models are
+// looked up, otherwise the size and quantization are guessed from the tag
+func PullPreflight(ctx context.Context, client *ollama.Client, name string) *PullEstimate {
+	est := &PullEstimate{Name: name}
+
+	if model, err := GetModelInfo(ctx, client, name); err == nil && model.Details.QuantizationLevel != "" {
+		est.Source = "installed"
+		est.ParameterCount = model.ModelInfo.ParameterCount
+		est.QuantizationLevel = model.Details.QuantizationLevel
+	} else {
+		est.Source = "tag"
+		est.ParameterCount, est.QuantizationLevel = guessFromTag(name)
+	}
+
+	if est.ParameterCount > 0 {
+		est.Estimation = tools.EstimateMemory(est.ParameterCount, tools.DefaultNumCtx, est.QuantizationLevel)
+	}
+
+	return est
+}
+
+// guessFromTag reads the parameter count and quantization from a model name
+// like `llama3.1:8b-instruct-q8_0`, falling back to the architectures
+// database for tags like `llama3.2:1b` or `phi4:latest`
+func guessFromTag(name string) (int64, string) {
+	var (
+		parameter_count    int64
+		quantization_level = defaultTagQuantization
+	)
+
+	// drop the registry and namespace, `registry.ollama.ai/library/llama3:8b`
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+
+	repo, tag, found := strings.Cut(strings.ToLower(name), ":")
+	if !found {
+		tag = "latest"
+	}
+
+	for _, part := range strings.Split(tag, "-") {
+		switch {
+		case tagSizeRe.MatchString(part):
+			parameter_count = ParseParameterSize(part)
+		case tagQuantRe.MatchString(part):
+			quantization_level = strings.ToUpper(strings.Replace(part, "fp16", "f16", 1))
+		}
+	}
+
+	// a known model gives the exact count, `8b` is just rounded
+	for _, arch := range architectures {
+		if arch.Name == repo+":"+tag {
+			return arch.ParameterCount, quantization_level
+		}
+	}
+
+	if parameter_count == 0 && tag == "latest" {
+		for _, arch := range architectures {
+			if strings.HasPrefix(arch.Name, repo+":") {
+				parameter_count = arch.ParameterCount
+				break
+			}
+		}
+	}
+
+	return parameter_count, quantization_level
+}
+
+func printPullEstimate(est *PullEstimate, hw settings.Hardware) {
+	if est.Estimation == nil {
+		fmt.Printf("%s: size unknown, skipping memory check\n", est.Name)
+		return
+	}
+
+	mem := est.Estimation
+	fmt.Printf("%s: %s %s (%s), GPU RAM %.2f GB, System RAM %.2f GB at %d tokens\n",
+		est.Name,
+		tools.FormatParamCount(est.ParameterCount),
+		est.QuantizationLevel,
+		est.Source,
+		mem.GPURAM,
+		mem.SystemRAM,
+		tools.DefaultNumCtx)
+
+	switch {
+	case hw.RAM > 0 && mem.SystemRAM > hw.RAM:
+		fmt.Printf("  Warning: needs %.2f GB of system RAM, only %.2f GB configured, it won't fit\n", mem.SystemRAM, hw.RAM)
+	case hw.VRAM > 0 && mem.GPURAM > hw.VRAM:
+		fmt.Printf("  Warning: needs %.2f GB of VRAM, only %.2f GB configured, it will be partially offloaded to CPU\n", mem.GPURAM, hw.VRAM)
+	}
+}
+
+// PullModels pulls the models, at most concurrency at a time, rendering the
+// progress of every layer to out
+func PullModels(ctx context.Context, client *ollama.Client, names []string, concurrency int, insecure bool, out io.Writer) []*PullResult {
+	var (
+		results = make([]*PullResult, len(names))
+		sem     = make(chan struct{}, max(concurrency, 1))
+		wg      sync.WaitGroup
+		pw      = progress.NewWriter()
+		done    = make(chan struct{})
+	)
+
+	pw.SetOutputWriter(out)
+	pw.SetAutoStop(false)
+	pw.SetMessageLength(40)
+	pw.SetTrackerLength(30)
+	pw.SetUpdateFrequency(100 * time.Millisecond)
+	pw.Style().Visibility.ETA = true
+	pw.Style().Visibility.Value = true
+
+	go func() {
+		pw.Render()
+		close(done)
+	}()
+
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				results[i] = &PullResult{Name: name, Error: ctx.Err()}
+				return
+			}
+
+			results[i] = pullModel(ctx, client, name, insecure, pw)
+		}(i, name)
+	}
+
+	wg.Wait()
+
+	for !pw.IsRenderInProgress() {
+		time.Sleep(10 * time.Millisecond)
+	}
+	pw.Stop()
+	<-done
+
+	return results
+}
+
+func pullModel(ctx context.Context, client *ollama.Client, name string, insecure bool, pw progress.Writer) *PullResult {
+	var (
+		result   = &PullResult{Name: name}
+		start    = time.Now()
+		layers   = map[string]*progress.Tracker{}
+		statuses = &progress.Tracker{Message: name, Units: progress.UnitsDefault}
+	)
+
+	pw.AppendTracker(statuses)
+
+	err := client.Pull(ctx, &ollama.PullRequest{Model: name, Insecure: insecure}, func(p *ollama.ProgressResponse) error {
+		result.Status = p.Status
+
+		if p.Digest == "" || p.Total == 0 {
+			statuses.UpdateMessage(name + ": " + p.Status)
+			return nil
+		}
+
+		layer, ok := layers[p.Digest]
+		if !ok {
+			layer = &progress.Tracker{
+				Message: fmt.Sprintf("%s %s", name, shortDigest(p.Digest)),
+				Total:   p.Total,
+				Units:   progress.UnitsBytes,
+			}
+			layers[p.Digest] = layer
+			result.Size += p.Total
+			pw.AppendTracker(layer)
+		}
+
+		layer.SetValue(p.Completed)
+		if p.Completed >= p.Total {
+			layer.MarkAsDone()
+		}
+
+		return nil
+	})
+
+	result.Duration = time.Since(start)
+
+	if err != nil {
+		result.Error = err
+		statuses.UpdateMessage(name + ": " + err.Error())
+		statuses.MarkAsErrored()
+		for _, layer := range layers {
+			if !layer.IsDone() {
+				layer.MarkAsErrored()
+			}
+		}
+		return result
+	}
+
+	for _, layer := range layers {
+		layer.MarkAsDone()
+	}
+	statuses.MarkAsDone()
+
+	return result
+}
+
+// shortDigest returns the first 12 characters of a `sha256:...` digest
+func shortDigest(digest string) string {
+	digest = strings.TrimPrefix(digest, "sha256:")
+	if len(digest) > 12 {
+		return digest[:12]
+	}
+	return digest
+}
+
+func pullSummary(results []*PullResult) {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Model", "Status", "Size", "Time"})
+
+	failed := 0
+	for _, r := range results {
+		status := r.Status
+		if r.Error != nil {
+			status = r.Error.Error()
+			failed++
+		}
+
+		t.AppendRow(table.Row{
+			r.Name,
+			status,
+			text.AlignRight.Apply(fmt.Sprintf("%.2f Gb", float64(r.Size)/ONE_GB), 10),
+			text.AlignRight.Apply(r.Duration.Round(time.Second).String(), 8),
+		})
+	}
+
+	t.AppendFooter(table.Row{fmt.Sprintf("%d pulled, %d failed", len(results)-failed, failed)})
+	t.Render()
+}
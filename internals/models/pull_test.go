@@ -0,0 +1,114 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/padiazg/ollama-tools/models/ollama"
+	"github.com/padiazg/ollama-tools/models/ollama/ollamatest"
+	"github.com/stretchr/testify/assert"
+)
+
+// pullClient talks to a fake server with the default models plus a few to
+// pull, `broken` fails half way. active and peak count the pulls in flight
+func pullClient(active *int32, peak *int32) *ollama.Client {
+	fixture := ollamatest.DefaultFixture()
+	fixture.ChunkLatency = 5 * time.Millisecond
+	for _, name := range []string{"a", "b", "broken", "c"} {
+		fixture.Models = append(fixture.Models, &ollamatest.Model{Name: name, Digest: strings.Repeat(name[:1], 16), Size: 1024, Remote: true})
+	}
+	fixture.Errors = append(fixture.Errors, &ollamatest.Fault{Path: ollama.ApiPathPull, Model: "broken", Message: "max retries exceeded", Stream: true})
+
+	transport := ollamatest.New(fixture).Transport()
+
+	return ollama.NewClient("http://ollama:11434", ollama.WithTransport(&DryRunTransport{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		if r.URL.Path == ollama.ApiPathPull {
+			n := atomic.AddInt32(active, 1)
+			defer atomic.AddInt32(active, -1)
+			for {
+				p := atomic.LoadInt32(peak)
+				if n <= p || atomic.CompareAndSwapInt32(peak, p, n) {
+					break
+				}
+			}
+		}
+
+		return transport.RoundTrip(r)
+	}}))
+}
+
+func TestPullModels(t *testing.T) {
+	var (
+		active, peak int32
+		client       = pullClient(&active, &peak)
+		names        = []string{"a:latest", "b:latest", "broken:latest", "c:latest"}
+	)
+	defer client.Close()
+
+	got := PullModels(context.Background(), client, names, 2, false, io.Discard)
+	if !assert.Len(t, got, len(names)) {
+		return
+	}
+
+	for i, r := range got {
+		assert.Equal(t, names[i], r.Name)
+		if r.Name == "broken:latest" {
+			assert.ErrorContains(t, r.Error, "max retries exceeded")
+			continue
+		}
+
+		assert.NoError(t, r.Error)
+		assert.Equal(t, "success", r.Status)
+		assert.Equal(t, int64(1024), r.Size)
+	}
+
+	assert.LessOrEqual(t, peak, int32(2), "concurrency limit exceeded")
+}
+
+func TestPullPreflight(t *testing.T) {
+	var (
+		active, peak int32
+		client       = pullClient(&active, &peak)
+	)
+	defer client.Close()
+
+	installed := PullPreflight(context.Background(), client, modelPhi4)
+	assert.Equal(t, "installed", installed.Source)
+	assert.Equal(t, int64(14659507200), installed.ParameterCount)
+	assert.NotNil(t, installed.Estimation)
+
+	tag := PullPreflight(context.Background(), client, "llama3.1:70b-instruct-q8_0")
+	assert.Equal(t, "tag", tag.Source)
+	assert.Equal(t, int64(70_000_000_000), tag.ParameterCount)
+	assert.Equal(t, "Q8_0", tag.QuantizationLevel)
+
+	unknown := PullPreflight(context.Background(), client, "someone/custom:latest")
+	assert.Nil(t, unknown.Estimation)
+}
+
+func Test_guessFromTag(t *testing.T) {
+	tests := []struct {
+		name               string
+		parameter_count    int64
+		quantization_level string
+	}{
+		{name: "llama3.1:8b-instruct-fp16", parameter_count: 8_000_000_000, quantization_level: "F16"},
+		{name: "registry.ollama.ai/library/mixtral:8x7b", parameter_count: 56_000_000_000, quantization_level: defaultTagQuantization},
+		{name: "llama3.2:1b", parameter_count: 1235814400, quantization_level: defaultTagQuantization},
+		{name: "unknown", parameter_count: 0, quantization_level: defaultTagQuantization},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parameter_count, quantization_level := guessFromTag(tt.name)
+			assert.Equal(t, tt.parameter_count, parameter_count, fmt.Sprintf("parameter count for %s", tt.name))
+			assert.Equal(t, tt.quantization_level, quantization_level)
+		})
+	}
+}
@@ -0,0 +1,330 @@
+package models
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/padiazg/ollama-tools/models/ollama"
+)
+
+var (
+	fromBlobRe = regexp.MustCompile(`(?m)^FROM\s+\S*(sha256[-:][0-9a-f]{64})\s*$`)
+	ageRe      = regexp.MustCompile(`^(\d+)([dw])$`)
+	sizeRe     = regexp.MustCompile(`(?i)^(\d+(?:\.\d+)?)\s*([kmgt]?i?b?)$`)
+)
+
+// RemoveFilter selects the models to remove, a model must match one of the
+// patterns, if any, and every filter that is set
+type RemoveFilter struct {
+	Patterns   []string
+	OlderThan  time.Duration
+	LargerThan int64
+}
+
+func (f *RemoveFilter) IsEmpty() bool {
+	return len(f.Patterns) == 0 && f.OlderThan == 0 && f.LargerThan == 0
+}
+
+// RemoveItem is a model selected for removal
+type RemoveItem struct {
+	Tag ollama.TagModel
+	// SharedWith lists the models not being removed that use the same blob
+	SharedWith []string
+}
+
+// RemovePlan is the list of models to remove and the disk space freed
+type RemovePlan struct {
+	Items     []*RemoveItem
+	Reclaimed int64
+}
+
+// Remove deletes the models selected by the filter after printing the plan,
+// it asks for confirmation on in unless yes is set
+func Remove(ctx context.Context, client *ollama.Client, f RemoveFilter, dry_run bool, yes bool, in io.Reader) {
+	tags, err := GetTags(ctx, client)
+	if err != nil {
+		fmt.Printf("listing models: %+v\n", err)
+		return
+	}
+
+	plan, err := PlanRemove(tags.Models, modelBlobs(ctx, client, tags.Models), f, time.Now())
+	if err != nil {
+		fmt.Printf("selecting models: %+v\n", err)
+		return
+	}
+
+	if len(plan.Items) == 0 {
+		fmt.Println("No models matched")
+		return
+	}
+
+	printRemovePlan(plan)
+
+	if dry_run {
+		return
+	}
+
+	if !yes && !confirm(in, fmt.Sprintf("Remove %d models?", len(plan.Items))) {
+		fmt.Println("Aborted")
+		return
+	}
+
+	for _, item := range plan.Items {
+		if err := client.Delete(ctx, item.Tag.Name); err != nil {
+			fmt.Printf("removing %s: %+v\n", item.Tag.Name, err)
+			continue
+		}
+		fmt.Printf("deleted '%s'\n", item.Tag.Name)
+	}
+}
+
+// modelBlobs maps every model to the blob holding its weights, read from the
+// modelfile. Models that can't be read are keyed by their manifest digest
+func modelBlobs(ctx context.Context, client *ollama.Client, tags []ollama.TagModel) map[string]string {
+	blobs := make(map[string]string, len(tags))
+
+	for _, tag := range tags {
+		blobs[tag.Name] = "manifest:" + tag.Digest
+
+		model, err := client.Show(ctx, tag.Name)
+		if err != nil {
+			continue
+		}
+
+		if blob := weightsBlob(model.Modelfile); blob != "" {
+			blobs[tag.Name] = blob
+		}
+	}
+
+	return blobs
+}
+
+// weightsBlob returns the `sha256-...` blob in the FROM line of a modelfile
+func weightsBlob(modelfile string) string {
+	m := fromBlobRe.FindStringSubmatch(modelfile)
+	if m == nil {
+		return ""
+	}
+	return strings.Replace(m[1], ":", "-", 1)
+}
+
+// PlanRemove selects the models to remove and works out the space freed, a
+// blob only counts once, and not at all while a kept model still uses it
+func PlanRemove(tags []ollama.TagModel, blobs map[string]string, f RemoveFilter, now time.Time) (*RemovePlan, error) {
+	var (
+		plan  = &RemovePlan{}
+		sizes = map[string]int64{}
+		kept  = map[string][]string{}
+	)
+
+	if f.IsEmpty() {
+		return nil, fmt.Errorf("no model names, patterns or filters given")
+	}
+
+	for _, pattern := range f.Patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %s: %+v", pattern, err)
+		}
+	}
+
+	for _, tag := range tags {
+		ok, err := f.matches(&tag, now)
+		if err != nil {
+			return nil, err
+		}
+
+		blob := blobs[tag.Name]
+		if blob == "" {
+			blob = "manifest:" + tag.Digest
+		}
+
+		if !ok {
+			kept[blob] = append(kept[blob], tag.Name)
+			continue
+		}
+
+		plan.Items = append(plan.Items, &RemoveItem{Tag: tag})
+		if int64(tag.Size) > sizes[blob] {
+			sizes[blob] = int64(tag.Size)
+		}
+	}
+
+	for _, item := range plan.Items {
+		blob := blobs[item.Tag.Name]
+		if blob == "" {
+			blob = "manifest:" + item.Tag.Digest
+		}
+		item.SharedWith = kept[blob]
+	}
+
+	for blob, size := range sizes {
+		if len(kept[blob]) == 0 {
+			plan.Reclaimed += size
+		}
+	}
+
+	sort.Slice(plan.Items, func(i, j int) bool { return plan.Items[i].Tag.Name < plan.Items[j].Tag.Name })
+
+	return plan, nil
+}
+
+func (f *RemoveFilter) matches(tag *ollama.TagModel, now time.Time) (bool, error) {
+	if len(f.Patterns) > 0 && !matchesAny(f.Patterns, tag.Name) {
+		return false, nil
+	}
+
+	if f.LargerThan > 0 && int64(tag.Size) <= f.LargerThan {
+		return false, nil
+	}
+
+	if f.OlderThan > 0 {
+		modified, err := time.Parse(time.RFC3339Nano, tag.ModifiedAt)
+		if err != nil {
+			return false, fmt.Errorf("parsing %s modified_at: %+v", tag.Name, err)
+		}
+
+		if now.Sub(modified) <= f.OlderThan {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// matchesAny matches name against the patterns. A plain name without a tag
+// means `:latest`, like the ollama cli, a glob without a tag matches any tag.
+// Unlike path.Match a `*` also crosses the `/` of namespaced models
+func matchesAny(patterns []string, name string) bool {
+	repo, _, _ := strings.Cut(name, ":")
+
+	for _, pattern := range patterns {
+		target := name
+		if !strings.Contains(pattern, ":") {
+			if strings.ContainsAny(pattern, `*?[\`) {
+				target = repo
+			} else {
+				pattern += ":latest"
+			}
+		}
+
+		if ok, _ := path.Match(noSlash(pattern), noSlash(target)); ok {
+			return true
+		}
+	}
+
+	return false
+}
+
+// noSlash hides the `/` from path.Match, so `*` and `?` match it too
+func noSlash(s string) string {
+	return strings.ReplaceAll(s, "/", "\x00")
+}
+
+// ParseAge parses an age like 30d, 2w or any time.Duration like 12h
+func ParseAge(age string) (time.Duration, error) {
+	if age == "" {
+		return 0, nil
+	}
+
+	if m := ageRe.FindStringSubmatch(age); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		days := map[string]int{"d": 1, "w": 7}[m[2]]
+		return time.Duration(n*days) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(age)
+	if err != nil {
+		return 0, fmt.Errorf("invalid age %s, use something like 30d, 2w or 12h", age)
+	}
+
+	return d, nil
+}
+
+// ParseSize parses a size like 20GB, 500MB or 1.5T into bytes, units are
+// powers of 1024 as everywhere else in the app
+func ParseSize(size string) (int64, error) {
+	if size == "" {
+		return 0, nil
+	}
+
+	m := sizeRe.FindStringSubmatch(strings.TrimSpace(size))
+	if m == nil {
+		return 0, fmt.Errorf("invalid size %s, use something like 20GB or 500MB", size)
+	}
+
+	var (
+		n, _       = strconv.ParseFloat(m[1], 64)
+		multiplier = 1.0
+	)
+
+	if unit := strings.ToLower(m[2]); unit != "" {
+		switch unit[0] {
+		case 'k':
+			multiplier = 1 << 10
+		case 'm':
+			multiplier = 1 << 20
+		case 'g':
+			multiplier = 1 << 30
+		case 't':
+			multiplier = 1 << 40
+		}
+	}
+
+	return int64(n * multiplier), nil
+}
+
+func printRemovePlan(plan *RemovePlan) {
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Model", "Size", "Modified", "Note"})
+
+	for _, item := range plan.Items {
+		note := ""
+		if len(item.SharedWith) > 0 {
+			note = "weights shared with " + strings.Join(item.SharedWith, ", ")
+		}
+
+		modified := item.Tag.ModifiedAt
+		if m, err := time.Parse(time.RFC3339Nano, modified); err == nil {
+			modified = m.Format(time.DateOnly)
+		}
+
+		t.AppendRow(table.Row{
+			item.Tag.Name,
+			text.AlignRight.Apply(fmt.Sprintf("%.2f Gb", float64(item.Tag.Size)/ONE_GB), 10),
+			modified,
+			note,
+		})
+	}
+
+	t.AppendFooter(table.Row{"Reclaimed", fmt.Sprintf("%.2f Gb", float64(plan.Reclaimed)/ONE_GB)})
+	t.Render()
+}
+
+// confirm asks a yes/no question on stdout and reads the answer from in
+func confirm(in io.Reader, question string) bool {
+	fmt.Printf("%s [y/N] ", question)
+
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && answer == "" {
+		return false
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true
+	}
+
+	return false
+}
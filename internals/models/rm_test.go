@@ -0,0 +1,153 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/padiazg/ollama-tools/models/ollama"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanRemove(t *testing.T) {
+	var (
+		now  = time.Date(2025, 3, 12, 0, 0, 0, 0, time.UTC)
+		tags = []ollama.TagModel{
+			{Name: "qwen2.5:7b-q8_0", Size: 8 * ONE_GB, Digest: "d1", ModifiedAt: "2025-01-01T10:00:00-03:00"},
+			{Name: "qwen2.5-coder:7b-q8_0", Size: 8 * ONE_GB, Digest: "d2", ModifiedAt: "2025-03-10T10:00:00-03:00"},
+			{Name: "qwen2.5:7b", Size: 4 * ONE_GB, Digest: "d3", ModifiedAt: "2025-01-01T10:00:00-03:00"},
+			{Name: "mine:latest", Size: 8 * ONE_GB, Digest: "d4", ModifiedAt: "2025-01-01T10:00:00-03:00"},
+			{Name: "phi4:latest", Size: 9 * ONE_GB, Digest: "d5", ModifiedAt: "2025-01-01T10:00:00-03:00"},
+			{Name: "phi4:alias", Size: 9 * ONE_GB, Digest: "d5", ModifiedAt: "2025-01-01T10:00:00-03:00"},
+			{Name: "team/coder:7b", Size: ONE_GB, Digest: "d6", ModifiedAt: "2025-03-10T10:00:00-03:00"},
+			{Name: "hf.co/org/model:Q4_K_M", Size: ONE_GB, Digest: "d7", ModifiedAt: "2025-03-10T10:00:00-03:00"},
+		}
+		blobs = map[string]string{
+			"qwen2.5:7b-q8_0":       "sha256-q8",
+			"qwen2.5-coder:7b-q8_0": "sha256-coder",
+			"qwen2.5:7b":            "sha256-q4",
+			// a custom model built FROM qwen2.5:7b-q8_0
+			"mine:latest":            "sha256-q8",
+			"phi4:latest":            "sha256-phi4",
+			"phi4:alias":             "sha256-phi4",
+			"team/coder:7b":          "sha256-team",
+			"hf.co/org/model:Q4_K_M": "sha256-hf",
+		}
+		names = func(plan *RemovePlan) []string {
+			list := []string{}
+			for _, item := range plan.Items {
+				list = append(list, item.Tag.Name)
+			}
+			return list
+		}
+	)
+
+	tests := []struct {
+		name          string
+		filter        RemoveFilter
+		want          []string
+		wantReclaimed int64
+		wantErrorMsg  string
+	}{
+		{
+			name:          "glob-shared-blob",
+			filter:        RemoveFilter{Patterns: []string{"qwen2.5*:*-q8_0"}},
+			want:          []string{"qwen2.5-coder:7b-q8_0", "qwen2.5:7b-q8_0"},
+			wantReclaimed: 8 * ONE_GB,
+		},
+		{
+			name:          "aliases-count-once",
+			filter:        RemoveFilter{Patterns: []string{"phi4:*"}},
+			want:          []string{"phi4:alias", "phi4:latest"},
+			wantReclaimed: 9 * ONE_GB,
+		},
+		{
+			name:          "glob-namespaced",
+			filter:        RemoveFilter{Patterns: []string{"*coder*"}},
+			want:          []string{"qwen2.5-coder:7b-q8_0", "team/coder:7b"},
+			wantReclaimed: 9 * ONE_GB,
+		},
+		{
+			name:          "glob-namespace-prefix",
+			filter:        RemoveFilter{Patterns: []string{"hf.co/*"}},
+			want:          []string{"hf.co/org/model:Q4_K_M"},
+			wantReclaimed: ONE_GB,
+		},
+		{
+			name:          "plain-name-means-latest",
+			filter:        RemoveFilter{Patterns: []string{"phi4"}},
+			want:          []string{"phi4:latest"},
+			wantReclaimed: 0,
+		},
+		{
+			name:          "older-and-larger",
+			filter:        RemoveFilter{OlderThan: 30 * 24 * time.Hour, LargerThan: 5 * ONE_GB},
+			want:          []string{"mine:latest", "phi4:alias", "phi4:latest", "qwen2.5:7b-q8_0"},
+			wantReclaimed: 17 * ONE_GB,
+		},
+		{
+			name:         "empty",
+			wantErrorMsg: "no model names",
+		},
+		{
+			name:         "bad-pattern",
+			filter:       RemoveFilter{Patterns: []string{"qwen["}},
+			wantErrorMsg: "invalid pattern",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := PlanRemove(tags, blobs, tt.filter, now)
+			if tt.wantErrorMsg != "" {
+				assert.ErrorContains(t, err, tt.wantErrorMsg)
+				return
+			}
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.want, names(got))
+				assert.Equal(t, tt.wantReclaimed, got.Reclaimed)
+			}
+		})
+	}
+}
+
+func Test_weightsBlob(t *testing.T) {
+	digest := strings.Repeat("ab", 32)
+
+	assert.Equal(t, "sha256-"+digest, weightsBlob("# Modelfile generated by \"ollama show\"\nFROM /usr/share/ollama/.ollama/models/blobs/sha256-"+digest+"\nTEMPLATE \"\"\"{{ .Prompt }}\"\"\"\n"))
+	assert.Equal(t, "", weightsBlob("FROM llama3.1:latest\n"))
+}
+
+func TestParseAge(t *testing.T) {
+	for age, want := range map[string]time.Duration{
+		"":    0,
+		"30d": 30 * 24 * time.Hour,
+		"2w":  14 * 24 * time.Hour,
+		"12h": 12 * time.Hour,
+	} {
+		got, err := ParseAge(age)
+		assert.NoError(t, err)
+		assert.Equal(t, want, got, age)
+	}
+
+	_, err := ParseAge("a month")
+	assert.ErrorContains(t, err, "invalid age")
+}
+
+func TestParseSize(t *testing.T) {
+	for size, want := range map[string]int64{
+		"":      0,
+		"20GB":  20 * ONE_GB,
+		"500mb": 500 << 20,
+		"1.5T":  3 << 39,
+		"1024":  1024,
+	} {
+		got, err := ParseSize(size)
+		assert.NoError(t, err)
+		assert.Equal(t, want, got, size)
+	}
+
+	_, err := ParseSize("big")
+	assert.ErrorContains(t, err, "invalid size")
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/padiazg/ollama-tools/internals/tools"
+	"github.com/padiazg/ollama-tools/models/ollama"
+)
+
+// Simulate prints the context and KV cache growth of a conversation
+func Simulate(ctx context.Context, client *ollama.Client, model_name string, transcript string, num_ctx int, chars_per_token float64) {
+	messages, err := LoadTranscript(transcript)
+	if err != nil {
+		fmt.Printf("loading transcript: %+v\n", err)
+		return
+	}
+
+	model, err := GetModelInfo(ctx, client, model_name)
+	if err != nil {
+		fmt.Printf("getting model %s info: %+v\n", model_name, err)
+		return
+	}
+
+	if num_ctx <= 0 {
+		num_ctx = tools.DefaultNumCtx
+	}
+
+	fmt.Printf("Model: %s\n", model_name)
+	turns := tools.SimulateConversation(&model.ModelInfo, model.Details.QuantizationLevel, messages, num_ctx, chars_per_token)
+	tools.PrintSimulation(turns, num_ctx)
+}
+
+// LoadTranscript reads chat messages from a JSON file, either a plain array
+// of messages or a chat request with a `messages` field
+func LoadTranscript(path string) ([]ollama.Message, error) {
+	var (
+		messages []ollama.Message
+		request  struct {
+			Messages []ollama.Message `json:"messages"`
+		}
+	)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %+v", path, err)
+	}
+
+	if err := json.Unmarshal(data, &messages); err != nil {
+		if err := json.Unmarshal(data, &request); err != nil {
+			return nil, fmt.Errorf("decoding %s: %+v", path, err)
+		}
+		messages = request.Messages
+	}
+
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("no messages found in %s", path)
+	}
+
+	// objects of another shape decode as messages without a role
+	for i, m := range messages {
+		if m.Role == "" {
+			return nil, fmt.Errorf("message %d in %s has no role", i+1, path)
+		}
+	}
+
+	return messages, nil
+}
@@ -0,0 +1,77 @@
+package models
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/padiazg/ollama-tools/models/ollama"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadTranscript(t *testing.T) {
+	var (
+		dir  = t.TempDir()
+		want = []ollama.Message{
+			{Role: "user", Content: "hi"},
+			{Role: "assistant", Content: "hello"},
+		}
+		tests = []struct {
+			name         string
+			content      string
+			wantErrorMsg string
+		}{
+			{
+				name:    "array",
+				content: `[{"role":"user","content":"hi"},{"role":"assistant","content":"hello"}]`,
+			},
+			{
+				name:    "chat-request",
+				content: `{"model":"phi4","messages":[{"role":"user","content":"hi"},{"role":"assistant","content":"hello"}]}`,
+			},
+			{
+				name:         "invalid",
+				content:      `not json`,
+				wantErrorMsg: "decoding",
+			},
+			{
+				name:         "no-messages-field",
+				content:      `{"model":"phi4","prompt":"hi"}`,
+				wantErrorMsg: "no messages found",
+			},
+			{
+				name:         "empty-array",
+				content:      `[]`,
+				wantErrorMsg: "no messages found",
+			},
+			{
+				name:         "wrong-shape",
+				content:      `{"messages":[{"text":"hi"}]}`,
+				wantErrorMsg: "message 1",
+			},
+			{
+				name:         "messages-not-a-list",
+				content:      `{"messages":"hi"}`,
+				wantErrorMsg: "decoding",
+			},
+		}
+	)
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.name+".json")
+			_ = os.WriteFile(path, []byte(tt.content), 0o644)
+
+			got, err := LoadTranscript(path)
+			if tt.wantErrorMsg != "" {
+				assert.ErrorContains(t, err, tt.wantErrorMsg)
+				return
+			}
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, want, got)
+			}
+		})
+	}
+}
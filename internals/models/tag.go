@@ -0,0 +1,157 @@
+package models
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jedib0t/go-pretty/v6/table"
+	"github.com/jedib0t/go-pretty/v6/text"
+	"github.com/padiazg/ollama-tools/models/ollama"
+)
+
+// AliasGroup is a set of names pointing to the same manifest
+type AliasGroup struct {
+	Digest string
+	Size   int64
+	Names  []string
+}
+
+// TagCreate creates alias as another name for source, an existing alias is
+// only replaced with force
+func TagCreate(ctx context.Context, client *ollama.Client, source string, alias string, force bool) error {
+	if !force {
+		tags, err := GetTags(ctx, client)
+		if err != nil {
+			return err
+		}
+
+		if tag := findTag(tags.Models, alias); tag != nil {
+			return fmt.Errorf("%s already exists, use --force to replace it", tag.Name)
+		}
+	}
+
+	if err := client.Copy(ctx, source, alias); err != nil {
+		return fmt.Errorf("copying %s to %s: %+v", source, alias, err)
+	}
+
+	return nil
+}
+
+// TagMove renames alias to target, copying it and then removing the old
+// name. When digest is given the alias is only moved if it points to it, an
+// existing target is only replaced with force
+func TagMove(ctx context.Context, client *ollama.Client, alias string, target string, digest string, force bool) error {
+	tags, err := GetTags(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	tag := findTag(tags.Models, alias)
+	if tag == nil {
+		return fmt.Errorf("model %s not found", alias)
+	}
+
+	if digest != "" && !digestMatches(tag.Digest, digest) {
+		return fmt.Errorf("%s points to %s, not %s", alias, shortDigest(tag.Digest), digest)
+	}
+
+	// copying onto itself and deleting the name would remove the model
+	if withTag(target) == withTag(tag.Name) {
+		return fmt.Errorf("%s is already named %s", tag.Name, target)
+	}
+
+	if existing := findTag(tags.Models, target); existing != nil && !force {
+		return fmt.Errorf("%s already exists, use --force to replace it", existing.Name)
+	}
+
+	if err := client.Copy(ctx, tag.Name, target); err != nil {
+		return fmt.Errorf("copying %s to %s: %+v", tag.Name, target, err)
+	}
+
+	if err := client.Delete(ctx, tag.Name); err != nil {
+		return fmt.Errorf("%s copied to %s but removing it failed: %+v", tag.Name, target, err)
+	}
+
+	return nil
+}
+
+// TagList prints the models grouped by the manifest they point to
+func TagList(ctx context.Context, client *ollama.Client) {
+	tags, err := GetTags(ctx, client)
+	if err != nil {
+		fmt.Printf("listing models: %+v\n", err)
+		return
+	}
+
+	t := table.NewWriter()
+	t.SetOutputMirror(os.Stdout)
+	t.AppendHeader(table.Row{"Digest", "Size", "Names"})
+
+	for _, group := range GroupAliases(tags.Models) {
+		t.AppendRow(table.Row{
+			shortDigest(group.Digest),
+			text.AlignRight.Apply(fmt.Sprintf("%.2f Gb", float64(group.Size)/ONE_GB), 10),
+			strings.Join(group.Names, "\n"),
+		})
+	}
+
+	t.SetStyle(table.StyleLight)
+	t.Style().Options.SeparateRows = true
+	t.Render()
+}
+
+// GroupAliases groups the models by digest, groups with more names go first
+func GroupAliases(tags []ollama.TagModel) []*AliasGroup {
+	var (
+		groups = []*AliasGroup{}
+		index  = map[string]*AliasGroup{}
+	)
+
+	for _, tag := range tags {
+		group, ok := index[tag.Digest]
+		if !ok {
+			group = &AliasGroup{Digest: tag.Digest, Size: int64(tag.Size)}
+			index[tag.Digest] = group
+			groups = append(groups, group)
+		}
+		group.Names = append(group.Names, tag.Name)
+	}
+
+	for _, group := range groups {
+		sort.Strings(group.Names)
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		if len(groups[i].Names) != len(groups[j].Names) {
+			return len(groups[i].Names) > len(groups[j].Names)
+		}
+		return groups[i].Names[0] < groups[j].Names[0]
+	})
+
+	return groups
+}
+
+// findTag looks up a model by name, a name without a tag means `:latest`
+func findTag(tags []ollama.TagModel, name string) *ollama.TagModel {
+	name = withTag(name)
+
+	for i := range tags {
+		if tags[i].Name == name || tags[i].Model == name {
+			return &tags[i]
+		}
+	}
+
+	return nil
+}
+
+// digestMatches compares a digest with an expected one, which may be
+// shortened and come with or without the `sha256:` prefix
+func digestMatches(digest string, expected string) bool {
+	digest = strings.TrimPrefix(digest, "sha256:")
+	expected = strings.TrimPrefix(strings.TrimPrefix(expected, "sha256:"), "sha256-")
+
+	return len(expected) >= 6 && strings.HasPrefix(digest, expected)
+}
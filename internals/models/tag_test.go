@@ -0,0 +1,188 @@
+package models
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/padiazg/ollama-tools/models/ollama"
+	"github.com/padiazg/ollama-tools/models/ollama/ollamatest"
+	"github.com/stretchr/testify/assert"
+)
+
+// tagClient talks to a fake server with the team/coder:current and
+// team/coder:stable aliases, the copy and delete requests are recorded in
+// calls
+func tagClient(calls *[]string) *ollama.Client {
+	fake := ollamatest.New(&ollamatest.Fixture{
+		Models: []*ollamatest.Model{
+			{Name: "team/coder:current", Digest: "2b0496514337a3d5901f1d253d01726c890b721e891335a56d6e08cedf3e2cb0", Size: 1024},
+			{Name: "team/coder:stable", Digest: "0a8c266910e3f0a6e3e6e8f6e9b1b7e5a2b8a4e8f1c3d2b1a0f9e8d7c6b5a4f3", Size: 2048},
+		},
+	}).Transport()
+
+	return ollama.NewClient("http://ollama:11434", ollama.WithTransport(&DryRunTransport{RoundTripFn: func(r *http.Request) (*http.Response, error) {
+		if r.URL.Path == ollama.ApiPathCopy || r.URL.Path == ollama.ApiPathDelete {
+			data, _ := io.ReadAll(r.Body)
+			r.Body = io.NopCloser(bytes.NewReader(data))
+
+			req := map[string]string{}
+			_ = json.Unmarshal(data, &req)
+			if r.URL.Path == ollama.ApiPathCopy {
+				*calls = append(*calls, "copy "+req["source"]+" "+req["destination"])
+			} else {
+				*calls = append(*calls, r.Method+" "+req["model"])
+			}
+		}
+
+		return fake.RoundTrip(r)
+	}}))
+}
+
+func TestTagCreate(t *testing.T) {
+	tests := []struct {
+		name         string
+		alias        string
+		force        bool
+		wantCalls    []string
+		wantErrorMsg string
+	}{
+		{
+			name:      "create",
+			alias:     "team/coder:previous",
+			wantCalls: []string{"copy team/coder:current team/coder:previous"},
+		},
+		{
+			name:         "alias-exists",
+			alias:        "team/coder:stable",
+			wantErrorMsg: "team/coder:stable already exists",
+		},
+		{
+			name:      "alias-exists-force",
+			alias:     "team/coder:stable",
+			force:     true,
+			wantCalls: []string{"copy team/coder:current team/coder:stable"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			client := tagClient(&calls)
+			defer client.Close()
+
+			err := TagCreate(context.Background(), client, "team/coder:current", tt.alias, tt.force)
+			if tt.wantErrorMsg != "" {
+				assert.ErrorContains(t, err, tt.wantErrorMsg)
+				assert.Empty(t, calls)
+				return
+			}
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.wantCalls, calls)
+			}
+		})
+	}
+}
+
+func TestTagMove(t *testing.T) {
+	tests := []struct {
+		name         string
+		alias        string
+		target       string
+		digest       string
+		force        bool
+		wantCalls    []string
+		wantErrorMsg string
+	}{
+		{
+			name:      "move",
+			alias:     "team/coder:current",
+			digest:    "sha256:2b0496",
+			wantCalls: []string{"copy team/coder:current team/coder:previous", "DELETE team/coder:current"},
+		},
+		{
+			name:         "digest-mismatch",
+			alias:        "team/coder:current",
+			digest:       "0a8c2669",
+			wantErrorMsg: "not 0a8c2669",
+		},
+		{
+			name:         "not-found",
+			alias:        "team/coder",
+			wantErrorMsg: "not found",
+		},
+		{
+			name:         "target-exists",
+			alias:        "team/coder:current",
+			target:       "team/coder:stable",
+			wantErrorMsg: "team/coder:stable already exists",
+		},
+		{
+			name:      "target-exists-force",
+			alias:     "team/coder:current",
+			target:    "team/coder:stable",
+			force:     true,
+			wantCalls: []string{"copy team/coder:current team/coder:stable", "DELETE team/coder:current"},
+		},
+		{
+			name:         "same-name",
+			alias:        "team/coder:current",
+			target:       "team/coder:current",
+			wantErrorMsg: "already named",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []string
+			client := tagClient(&calls)
+			defer client.Close()
+
+			if tt.target == "" {
+				tt.target = "team/coder:previous"
+			}
+			err := TagMove(context.Background(), client, tt.alias, tt.target, tt.digest, tt.force)
+			if tt.wantErrorMsg != "" {
+				assert.ErrorContains(t, err, tt.wantErrorMsg)
+				assert.Empty(t, calls)
+				return
+			}
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.wantCalls, calls)
+
+				tags, err := client.Tags(context.Background())
+				if assert.NoError(t, err) {
+					assert.NotNil(t, findTag(tags.Models, tt.target))
+					assert.Nil(t, findTag(tags.Models, tt.alias))
+				}
+			}
+		})
+	}
+}
+
+func TestGroupAliases(t *testing.T) {
+	got := GroupAliases([]ollama.TagModel{
+		{Name: "phi4:latest", Digest: "d1", Size: 10},
+		{Name: "qwen2.5:7b", Digest: "d2", Size: 5},
+		{Name: "team/chat:current", Digest: "d1", Size: 10},
+	})
+
+	if assert.Len(t, got, 2) {
+		assert.Equal(t, &AliasGroup{Digest: "d1", Size: 10, Names: []string{"phi4:latest", "team/chat:current"}}, got[0])
+		assert.Equal(t, []string{"qwen2.5:7b"}, got[1].Names)
+	}
+}
+
+func Test_digestMatches(t *testing.T) {
+	digest := "2b0496514337a3d5901f1d253d01726c890b721e891335a56d6e08cedf3e2cb0"
+
+	assert.True(t, digestMatches(digest, "2b0496"))
+	assert.True(t, digestMatches(digest, "sha256:2b0496514337"))
+	assert.False(t, digestMatches(digest, "2b04"), "too short")
+	assert.False(t, digestMatches(digest, "0a8c266910"))
+}
@@ -0,0 +1,225 @@
+package tools
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+const (
+	defaultChartHeight = 16
+	minChartContext    = 1024
+	chartGPUMarkers    = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	chartSystemMarkers = "abcdefghijklmnopqrstuvwxyz"
+	chartVRAMMarker    = '='
+	chartRAMMarker     = '-'
+	chartOverlapMarker = '#'
+)
+
+type ChartSeries struct {
+	Name   string
+	Marker rune
+	// Values are aligned with Chart.XLabels, NaN means no value
+	Values []float64
+}
+
+// ChartLine is an horizontal line across the chart, like a memory budget
+type ChartLine struct {
+	Name   string
+	Marker rune
+	Value  float64
+}
+
+type Chart struct {
+	Title   string
+	Unit    string
+	XLabel  string
+	XLabels []string
+	Series  []ChartSeries
+	Lines   []ChartLine
+	Height  int
+}
+
+// MemoryChartEntry is a model to be charted
+type MemoryChartEntry struct {
+	Name              string
+	ParameterCount    int64
+	ContextLength     int
+	QuantizationLevel string
+}
+
+// ChartContextLengths returns the powers of two from 1024 up to max_context,
+// max_context is added at the end when it's not a power of two
+func ChartContextLengths(max_context int) []int {
+	lengths := []int{}
+
+	for c := minChartContext; c < max_context; c *= 2 {
+		lengths = append(lengths, c)
+	}
+
+	return append(lengths, max(max_context, minChartContext))
+}
+
+// MemoryChart charts the GPU and system RAM from EstimateMemory against the
+// context length for every entry. vram and ram are the budgets in GB, zero
+// values are not drawn
+func MemoryChart(entries []MemoryChartEntry, vram, ram float64) *Chart {
+	var (
+		max_context = 0
+		chart       = &Chart{
+			Title:  "Memory vs context length",
+			Unit:   "GB",
+			XLabel: "context length (tokens)",
+		}
+	)
+
+	for _, e := range entries {
+		max_context = max(max_context, e.ContextLength)
+	}
+
+	lengths := ChartContextLengths(max_context)
+	for _, l := range lengths {
+		chart.XLabels = append(chart.XLabels, formatContextLength(l))
+	}
+
+	for i, e := range entries {
+		var (
+			gpu    = ChartSeries{Name: e.Name + " GPU RAM", Marker: chartMarker(chartGPUMarkers, i)}
+			system = ChartSeries{Name: e.Name + " System RAM", Marker: chartMarker(chartSystemMarkers, i)}
+		)
+
+		for _, l := range lengths {
+			if l > max(e.ContextLength, minChartContext) {
+				gpu.Values = append(gpu.Values, math.NaN())
+				system.Values = append(system.Values, math.NaN())
+				continue
+			}
+
+			mem := EstimateMemory(e.ParameterCount, l, e.QuantizationLevel)
+			gpu.Values = append(gpu.Values, mem.GPURAM)
+			system.Values = append(system.Values, mem.SystemRAM)
+		}
+
+		chart.Series = append(chart.Series, gpu, system)
+	}
+
+	if vram > 0 {
+		chart.Lines = append(chart.Lines, ChartLine{Name: "VRAM budget", Marker: chartVRAMMarker, Value: vram})
+	}
+
+	if ram > 0 {
+		chart.Lines = append(chart.Lines, ChartLine{Name: "RAM budget", Marker: chartRAMMarker, Value: ram})
+	}
+
+	return chart
+}
+
+func chartMarker(markers string, i int) rune {
+	return rune(markers[i%len(markers)])
+}
+
+func formatContextLength(l int) string {
+	if l >= 1024 && l%1024 == 0 {
+		return fmt.Sprintf("%dK", l/1024)
+	}
+	return fmt.Sprintf("%d", l)
+}
+
+// RenderChart draws the chart as text, each row covers the same range of
+// values and points falling in it are drawn with the series marker
+func RenderChart(w io.Writer, c *Chart) {
+	var (
+		height = c.Height
+		top    = 0.0
+		col    = 1
+	)
+
+	if height <= 0 {
+		height = defaultChartHeight
+	}
+
+	for _, s := range c.Series {
+		for _, v := range s.Values {
+			if !math.IsNaN(v) {
+				top = max(top, v)
+			}
+		}
+	}
+
+	for _, l := range c.Lines {
+		top = max(top, l.Value)
+	}
+
+	if top == 0 {
+		fmt.Fprintln(w, "nothing to chart")
+		return
+	}
+	top *= 1.05
+
+	for _, l := range c.XLabels {
+		col = max(col, len(l)+1)
+	}
+
+	var (
+		step  = top / float64(height)
+		width = col * len(c.XLabels)
+		grid  = make([][]rune, height)
+		used  = map[[2]int]bool{}
+	)
+
+	rowOf := func(v float64) int {
+		return min(int(v/step), height-1)
+	}
+
+	for r := range grid {
+		grid[r] = []rune(strings.Repeat(" ", width))
+	}
+
+	for _, l := range c.Lines {
+		r := rowOf(l.Value)
+		for x := range grid[r] {
+			grid[r][x] = l.Marker
+		}
+	}
+
+	for _, s := range c.Series {
+		for i, v := range s.Values {
+			if math.IsNaN(v) {
+				continue
+			}
+			cell := [2]int{rowOf(v), i*col + col/2}
+			if used[cell] {
+				grid[cell[0]][cell[1]] = chartOverlapMarker
+				continue
+			}
+			used[cell] = true
+			grid[cell[0]][cell[1]] = s.Marker
+		}
+	}
+
+	if c.Title != "" {
+		fmt.Fprintf(w, "%s\n\n", c.Title)
+	}
+
+	for r := height - 1; r >= 0; r-- {
+		fmt.Fprintf(w, "%8.2f %s |%s\n", float64(r+1)*step, c.Unit, string(grid[r]))
+	}
+
+	fmt.Fprintf(w, "%11s +%s\n", "", strings.Repeat("-", width))
+	fmt.Fprintf(w, "%11s  ", "")
+	for _, l := range c.XLabels {
+		fmt.Fprintf(w, "%*s", -col, strings.Repeat(" ", (col-len(l))/2)+l)
+	}
+	fmt.Fprintf(w, "\n%11s  %s\n\n", "", c.XLabel)
+
+	for _, s := range c.Series {
+		fmt.Fprintf(w, "  %c  %s\n", s.Marker, s.Name)
+	}
+
+	for _, l := range c.Lines {
+		fmt.Fprintf(w, "  %c  %s (%.2f %s)\n", l.Marker, l.Name, l.Value, c.Unit)
+	}
+
+	fmt.Fprintf(w, "  %c  overlapping points\n", chartOverlapMarker)
+}
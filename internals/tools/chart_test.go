@@ -0,0 +1,73 @@
+package tools
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChartContextLengths(t *testing.T) {
+	tests := []struct {
+		name        string
+		max_context int
+		want        []int
+	}{
+		{name: "power-of-two", max_context: 8192, want: []int{1024, 2048, 4096, 8192}},
+		{name: "not-power-of-two", max_context: 5000, want: []int{1024, 2048, 4096, 5000}},
+		{name: "small", max_context: 512, want: []int{1024}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ChartContextLengths(tt.max_context))
+		})
+	}
+}
+
+func TestMemoryChart(t *testing.T) {
+	chart := MemoryChart([]MemoryChartEntry{
+		{Name: "big", ParameterCount: 8030261312, ContextLength: 8192, QuantizationLevel: "Q4_K_M"},
+		{Name: "small", ParameterCount: 136727040, ContextLength: 2048, QuantizationLevel: "F16"},
+	}, 12, 0)
+
+	assert.Equal(t, []string{"1K", "2K", "4K", "8K"}, chart.XLabels)
+	assert.Len(t, chart.Series, 4)
+	assert.Len(t, chart.Lines, 1, "zero budgets are not drawn")
+
+	// values past the model's context length are left out
+	small := chart.Series[2]
+	assert.False(t, math.IsNaN(small.Values[1]))
+	assert.True(t, math.IsNaN(small.Values[2]))
+}
+
+func TestRenderChart(t *testing.T) {
+	var (
+		out   = &bytes.Buffer{}
+		chart = &Chart{
+			Unit:    "GB",
+			XLabels: []string{"1K", "2K"},
+			Height:  4,
+			Series: []ChartSeries{
+				{Name: "gpu", Marker: 'A', Values: []float64{1, 3}},
+				{Name: "system", Marker: 'a', Values: []float64{1, math.NaN()}},
+			},
+			Lines: []ChartLine{{Name: "VRAM budget", Marker: '=', Value: 2}},
+		}
+	)
+
+	RenderChart(out, chart)
+	lines := strings.Split(out.String(), "\n")
+
+	assert.Contains(t, lines[0], "A")
+	assert.Contains(t, lines[1], "===")
+	assert.Contains(t, lines[2], "#", "both series share the first point")
+	assert.Contains(t, out.String(), "=  VRAM budget (2.00 GB)")
+
+	out.Reset()
+	RenderChart(out, &Chart{})
+	assert.Equal(t, "nothing to chart\n", out.String())
+}
@@ -0,0 +1,46 @@
+package tools
+
+import (
+	"fmt"
+
+	"github.com/padiazg/ollama-tools/models/ollama"
+)
+
+const (
+	// when the tensor layout is unknown (installed models) the biggest tensor,
+	// usually the token embeddings, is taken as a fraction of the parameters
+	largestTensorRatio = 0.05
+	// runtime, tokenizer and bookkeeping while converting
+	createOverhead = 0.5
+)
+
+// EstimateCreate estimates the resources needed to quantize a model with
+// `ollama create --quantize`. Tensors are converted one at a time, so the peak
+// RAM is driven by the biggest tensor dequantized to F32 plus its quantized copy.
+// source_size is in bytes, largest_tensor in elements (0 if unknown)
+func EstimateCreate(source_size int64, parameter_count int64, largest_tensor int64, quantization_level string) *ollama.CreateEstimation {
+	var (
+		est                 = &ollama.CreateEstimation{}
+		quantization_bits   = QuantizationBits(NormalizeQuantizationLevel(quantization_level))
+		bytes_per_parameter = BytesPerParameter(quantization_bits)
+	)
+
+	if largest_tensor <= 0 {
+		largest_tensor = int64(float64(parameter_count) * largestTensorRatio)
+	}
+
+	est.SourceSize = float64(source_size) / ONE_GB
+	est.OutputSize = (float64(parameter_count) * bytes_per_parameter) / ONE_GB
+	est.PeakDisk = est.SourceSize + est.OutputSize
+	est.PeakRAM = (float64(largest_tensor)*(4+bytes_per_parameter))/ONE_GB + createOverhead
+
+	return est
+}
+
+func PrintEstimatedCreatePlain(est *ollama.CreateEstimation) {
+	fmt.Printf("\n  Create Breakdown:\n")
+	fmt.Printf("    Source Size: %.2f GB\n", est.SourceSize)
+	fmt.Printf("    Final Size: %.2f GB\n", est.OutputSize)
+	fmt.Printf("    Peak Disk (source + output blobs): %.2f GB\n", est.PeakDisk)
+	fmt.Printf("    Peak RAM (during conversion): %.2f GB\n", est.PeakRAM)
+}
@@ -0,0 +1,164 @@
+package tools
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/padiazg/ollama-tools/models/ollama"
+)
+
+const (
+	// trainable parameters are kept as F32 master weights
+	adapterBytesPerParameter = 4.0
+	// AdamW keeps two F32 moments per trainable parameter
+	optimizerBytesPerParameter = 8.0
+	// Ollama loads adapters as F16
+	adapterLayerBytesPerParameter = 2.0
+)
+
+// FinetuneModules are the LoRA target modules we know how to size
+var FinetuneModules = []string{"q", "k", "v", "o", "gate", "up", "down"}
+
+type FinetuneConfig struct {
+	ParameterCount        int64
+	QuantizationLevel     string
+	Architecture          ollama.ModelInfo
+	Rank                  int
+	TargetModules         []string
+	BatchSize             int
+	SequenceLength        int
+	GradientCheckpointing bool
+}
+
+// ParseTargetModules accepts both short (q,v) and PEFT style (q_proj,v_proj)
+// module names, `all` selects every linear layer
+func ParseTargetModules(list string) ([]string, error) {
+	var modules []string
+
+	for _, m := range strings.Split(list, ",") {
+		m = strings.TrimSuffix(strings.TrimSpace(strings.ToLower(m)), "_proj")
+		if m == "" {
+			continue
+		}
+
+		if m == "all" {
+			return FinetuneModules, nil
+		}
+
+		found := false
+		for _, known := range FinetuneModules {
+			if m == known {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("unknown target module %q, expected one of %s", m, strings.Join(FinetuneModules, ","))
+		}
+		modules = append(modules, m)
+	}
+
+	return modules, nil
+}
+
+// LoRAParameters returns the trainable parameters of a LoRA adapter, each
+// target module of shape (in, out) adds rank * (in + out) per layer
+func LoRAParameters(arch *ollama.ModelInfo, rank int, modules []string) int64 {
+	var (
+		hidden   = int64(arch.EmbeddingLength)
+		ff       = int64(arch.FeedForwardLength)
+		headDim  = int64(arch.HeadDim())
+		qDim     = int64(arch.HeadCount) * headDim
+		kvDim    = int64(arch.KVHeads()) * headDim
+		perLayer int64
+	)
+
+	for _, m := range modules {
+		var in, out int64
+
+		switch m {
+		case "q":
+			in, out = hidden, qDim
+		case "k", "v":
+			in, out = hidden, kvDim
+		case "o":
+			in, out = qDim, hidden
+		case "gate", "up":
+			in, out = hidden, ff
+		case "down":
+			in, out = ff, hidden
+		}
+
+		perLayer += int64(rank) * (in + out)
+	}
+
+	return perLayer * int64(arch.BlockCount)
+}
+
+// EstimateFinetune estimates the GPU memory for a LoRA/QLoRA run. The frozen
+// base is held at the given quantization level, the adapter is trained in F32
+// with AdamW and activations follow Korthikanti et al. for 16-bit training:
+// s*b*h*(34 + 5*a*s/h) bytes per layer, or 2*s*b*h with gradient checkpointing
+func EstimateFinetune(cfg *FinetuneConfig) (*ollama.FinetuneEstimation, error) {
+	var (
+		est  = &ollama.FinetuneEstimation{}
+		arch = &cfg.Architecture
+	)
+
+	if arch.BlockCount == 0 || arch.EmbeddingLength == 0 || arch.HeadCount == 0 {
+		return nil, fmt.Errorf("architecture unknown, layers, hidden size and heads are required")
+	}
+
+	if arch.FeedForwardLength == 0 {
+		for _, m := range cfg.TargetModules {
+			if m == "gate" || m == "up" || m == "down" {
+				return nil, fmt.Errorf("feed forward length is required to target %s", m)
+			}
+		}
+	}
+
+	var (
+		quantization_bits   = QuantizationBits(NormalizeQuantizationLevel(cfg.QuantizationLevel))
+		bytes_per_parameter = BytesPerParameter(quantization_bits)
+		s                   = float64(cfg.SequenceLength)
+		b                   = float64(cfg.BatchSize)
+		h                   = float64(arch.EmbeddingLength)
+		a                   = float64(arch.HeadCount)
+		layers              = float64(arch.BlockCount)
+		activations         float64
+	)
+
+	est.AdapterParameters = LoRAParameters(arch, cfg.Rank, cfg.TargetModules)
+	est.BaseModelSize = (float64(cfg.ParameterCount) * bytes_per_parameter) / ONE_GB
+	est.AdapterWeights = (float64(est.AdapterParameters) * adapterBytesPerParameter) / ONE_GB
+	est.Gradients = (float64(est.AdapterParameters) * adapterBytesPerParameter) / ONE_GB
+	est.OptimizerState = (float64(est.AdapterParameters) * optimizerBytesPerParameter) / ONE_GB
+
+	fullLayer := s * b * h * (34 + 5*a*s/h)
+	if cfg.GradientCheckpointing {
+		// only the layer inputs are kept, one layer is recomputed at a time
+		activations = layers*2*s*b*h + fullLayer
+	} else {
+		activations = layers * fullLayer
+	}
+	est.Activations = activations / ONE_GB
+
+	gpuOverhead := est.BaseModelSize * .1
+	est.GPURAM = est.BaseModelSize + est.AdapterWeights + est.Gradients + est.OptimizerState + est.Activations + gpuOverhead
+	est.AdapterSize = (float64(est.AdapterParameters) * adapterLayerBytesPerParameter) / ONE_GB
+
+	return est, nil
+}
+
+func PrintEstimatedFinetunePlain(est *ollama.FinetuneEstimation) {
+	fmt.Printf("\n  Fine-tuning Breakdown:\n")
+	fmt.Printf("    Adapter Parameters: %s (%d)\n", FormatParamCount(est.AdapterParameters), est.AdapterParameters)
+	fmt.Printf("    Frozen Base Weights: %.2f GB\n", est.BaseModelSize)
+	fmt.Printf("    Adapter Weights: %.2f GB\n", est.AdapterWeights)
+	fmt.Printf("    Gradients: %.2f GB\n", est.Gradients)
+	fmt.Printf("    Optimizer State: %.2f GB\n", est.OptimizerState)
+	fmt.Printf("    Activations: %.2f GB\n", est.Activations)
+	fmt.Printf("    GPU VRAM: %.2f GB\n", est.GPURAM)
+	fmt.Printf("\n  Ollama ADAPTER layer: %.3f GB\n", est.AdapterSize)
+}
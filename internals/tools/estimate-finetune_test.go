@@ -0,0 +1,96 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/padiazg/ollama-tools/models/ollama"
+	"github.com/stretchr/testify/assert"
+)
+
+var llama3_8b = ollama.ModelInfo{
+	ParameterCount:    8030261312,
+	EmbeddingLength:   4096,
+	BlockCount:        32,
+	FeedForwardLength: 14336,
+	HeadCount:         32,
+	HeadCountKV:       8,
+}
+
+func TestParseTargetModules(t *testing.T) {
+	tests := []struct {
+		name         string
+		list         string
+		want         []string
+		wantErrorMsg string
+	}{
+		{name: "short", list: "q,v", want: []string{"q", "v"}},
+		{name: "peft", list: "q_proj, k_proj,gate_proj", want: []string{"q", "k", "gate"}},
+		{name: "all", list: "all", want: FinetuneModules},
+		{name: "unknown", list: "q,lm_head", wantErrorMsg: `unknown target module "lm_head"`},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseTargetModules(tt.list)
+			if tt.wantErrorMsg != "" {
+				assert.ErrorContains(t, err, tt.wantErrorMsg)
+				return
+			}
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestLoRAParameters(t *testing.T) {
+	tests := []struct {
+		name    string
+		rank    int
+		modules []string
+		want    int64
+	}{
+		// figures reported by PEFT's print_trainable_parameters for Llama 3 8B
+		{name: "q,v-r8", rank: 8, modules: []string{"q", "v"}, want: 3_407_872},
+		{name: "all-r16", rank: 16, modules: FinetuneModules, want: 41_943_040},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, LoRAParameters(&llama3_8b, tt.rank, tt.modules))
+		})
+	}
+}
+
+func TestEstimateFinetune(t *testing.T) {
+	cfg := &FinetuneConfig{
+		ParameterCount:    llama3_8b.ParameterCount,
+		QuantizationLevel: "Q4_K_M",
+		Architecture:      llama3_8b,
+		Rank:              16,
+		TargetModules:     FinetuneModules,
+		BatchSize:         1,
+		SequenceLength:    2048,
+	}
+
+	full, err := EstimateFinetune(cfg)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.InDelta(t, 3.74, full.BaseModelSize, 0.01)
+	assert.InDelta(t, full.AdapterWeights*2, full.OptimizerState, 0.0001)
+	assert.InDelta(t, full.AdapterWeights/2, full.AdapterSize, 0.0001)
+
+	cfg.GradientCheckpointing = true
+	checkpointed, err := EstimateFinetune(cfg)
+	if assert.NoError(t, err) {
+		assert.Less(t, checkpointed.Activations, full.Activations)
+	}
+
+	_, err = EstimateFinetune(&FinetuneConfig{QuantizationLevel: "F16"})
+	assert.ErrorContains(t, err, "architecture unknown")
+}
@@ -0,0 +1,110 @@
+package tools
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/padiazg/ollama-tools/models/ollama"
+)
+
+const (
+	// DefaultNumCtx is the context window Ollama allocates when num_ctx is not set
+	DefaultNumCtx = 2048
+	// DefaultCharsPerToken is a rough average for english text on BPE tokenizers
+	DefaultCharsPerToken = 4.0
+	// role markers and separators the chat template adds to every message
+	messageTemplateTokens = 4
+	// Ollama keeps the KV cache as F16 by default
+	kvCacheBytesPerElement = 2
+	simulationBarWidth     = 40
+)
+
+type Turn struct {
+	Index      int
+	Role       string
+	Tokens     int
+	Cumulative int
+	// KVCacheSize is the memory used by the tokens that fit in the window
+	KVCacheSize float64
+	Overflow    bool
+}
+
+// ApproximateTokens estimates the tokens of a message from its length
+func ApproximateTokens(content string, chars_per_token float64) int {
+	if chars_per_token <= 0 {
+		chars_per_token = DefaultCharsPerToken
+	}
+
+	return int(math.Ceil(float64(len([]rune(content)))/chars_per_token)) + messageTemplateTokens
+}
+
+// KVCacheSize returns the size in GB of the KV cache holding tokens. When the
+// architecture is known it uses 2 * layers * kv_heads * head_dim per token,
+// otherwise it falls back to the hidden size approximation in EstimateMemory
+func KVCacheSize(info *ollama.ModelInfo, tokens int, quantization_level string) float64 {
+	if info.BlockCount > 0 && info.KVHeads() > 0 && info.HeadDim() > 0 {
+		perToken := 2 * info.BlockCount * info.KVHeads() * info.HeadDim() * kvCacheBytesPerElement
+		return float64(perToken) * float64(tokens) / ONE_GB
+	}
+
+	var (
+		hiddenSize          = math.Sqrt(float64(info.ParameterCount) / 6)
+		bytes_per_parameter = BytesPerParameter(QuantizationBits(NormalizeQuantizationLevel(quantization_level)))
+	)
+
+	return (4 * hiddenSize * float64(tokens) * bytes_per_parameter) / ONE_GB
+}
+
+// SimulateConversation accumulates the context used turn by turn, a turn
+// overflows when the conversation no longer fits in num_ctx
+func SimulateConversation(info *ollama.ModelInfo, quantization_level string, messages []ollama.Message, num_ctx int, chars_per_token float64) []*Turn {
+	var (
+		turns      = make([]*Turn, 0, len(messages))
+		cumulative = 0
+	)
+
+	for i, m := range messages {
+		tokens := ApproximateTokens(m.Content, chars_per_token)
+		cumulative += tokens
+
+		turns = append(turns, &Turn{
+			Index:       i + 1,
+			Role:        m.Role,
+			Tokens:      tokens,
+			Cumulative:  cumulative,
+			KVCacheSize: KVCacheSize(info, min(cumulative, num_ctx), quantization_level),
+			Overflow:    cumulative > num_ctx,
+		})
+	}
+
+	return turns
+}
+
+func PrintSimulation(turns []*Turn, num_ctx int) {
+	warned := false
+
+	fmt.Printf("Context window: %d tokens\n\n", num_ctx)
+	for _, turn := range turns {
+		used := min(turn.Cumulative, num_ctx)
+		filled := used * simulationBarWidth / num_ctx
+
+		fmt.Printf("#%-3d %-9s %6d tok %7d/%d [%s%s] %3d%%  KV %.3f GB\n",
+			turn.Index,
+			turn.Role,
+			turn.Tokens,
+			turn.Cumulative,
+			num_ctx,
+			strings.Repeat("#", filled),
+			strings.Repeat(".", simulationBarWidth-filled),
+			turn.Cumulative*100/num_ctx,
+			turn.KVCacheSize,
+		)
+
+		if turn.Overflow && !warned {
+			warned = true
+			fmt.Printf("     ^ Warning: context overflows num_ctx at turn %d (%d > %d tokens), older messages will be truncated\n",
+				turn.Index, turn.Cumulative, num_ctx)
+		}
+	}
+}
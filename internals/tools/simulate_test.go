@@ -0,0 +1,38 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/padiazg/ollama-tools/models/ollama"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKVCacheSize(t *testing.T) {
+	// llama3 8B: 2 * 32 layers * 8 kv heads * 128 head dim * 2 bytes = 128KiB per token
+	assert.InDelta(t, 1.0, KVCacheSize(&llama3_8b, 8192, "Q4_K_M"), 0.0001)
+
+	// no architecture, falls back to the hidden size approximation
+	info := &ollama.ModelInfo{ParameterCount: llama3_8b.ParameterCount}
+	assert.InDelta(t, 0.14, KVCacheSize(info, 2048, "Q4_K_M"), 0.01)
+}
+
+func TestSimulateConversation(t *testing.T) {
+	messages := []ollama.Message{
+		{Role: "system", Content: strings.Repeat("a", 396)},
+		{Role: "user", Content: strings.Repeat("b", 796)},
+		{Role: "assistant", Content: strings.Repeat("c", 1196)},
+	}
+
+	turns := SimulateConversation(&llama3_8b, "Q4_K_M", messages, 500, 4)
+	if !assert.Len(t, turns, 3) {
+		return
+	}
+
+	assert.Equal(t, []int{103, 203, 303}, []int{turns[0].Tokens, turns[1].Tokens, turns[2].Tokens})
+	assert.Equal(t, []int{103, 306, 609}, []int{turns[0].Cumulative, turns[1].Cumulative, turns[2].Cumulative})
+	assert.Equal(t, []bool{false, false, true}, []bool{turns[0].Overflow, turns[1].Overflow, turns[2].Overflow})
+
+	// the KV cache stops growing once the window is full
+	assert.Equal(t, KVCacheSize(&llama3_8b, 500, "Q4_K_M"), turns[2].KVCacheSize)
+}
@@ -1,6 +1,9 @@
 package tools
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 func QuantizationBits(quantization_level string) int {
 	switch quantization_level {
@@ -17,7 +20,7 @@ func QuantizationBits(quantization_level string) int {
 	default:
 		// For GGUF/GGML models with unspecified quantization, default to 1.5 bytes average
 		if quantization_level != "" {
-			fmt.Printf("QuantizationBits %s not recognized, using defaults", quantization_level)
+			fmt.Printf("QuantizationBits %s not recognized, using defaults\n", quantization_level)
 		}
 		return 12
 	}
@@ -37,7 +40,7 @@ func BytesPerParameter(quantization_bits int) float64 {
 		return 4.0
 	default:
 		// For GGUF/GGML models with unspecified quantization, default to 1.5 bytes average
-		fmt.Printf("BytesPerParameter %d not recognized, using defaults", quantization_bits)
+		fmt.Printf("BytesPerParameter %d not recognized, using defaults\n", quantization_bits)
 		return 1.5
 	}
 }
@@ -55,26 +58,45 @@ func SystemRAMMultiplier(quantization_bits int) float64 {
 	case 32:
 		return 4.0 // FP32 needs more headroom
 	default:
-		fmt.Printf("SystemRAMMultiplier %d not recognized, using defaults", quantization_bits)
+		fmt.Printf("SystemRAMMultiplier %d not recognized, using defaults\n", quantization_bits)
 		return 1.5 // For GGUF/GGML models with unspecified quantization, default to 1.5 bytes average
 	}
 }
 
 // NormalizeQuantizationLevel returns a normalized quantization level
 func NormalizeQuantizationLevel(quantization_level string) string {
-	switch quantization_level[0] {
-	case 'Q': // ex: Q4_K_M
+	// OpenAI compatible servers don't always tell it
+	if quantization_level == "" {
+		return ""
+	}
+
+	switch {
+	case quantization_level[0] == 'Q' && len(quantization_level) >= 2: // ex: Q4_K_M
 		return quantization_level[0:2]
-	case 'F': // ex: F16, F32
+	case quantization_level[0] == 'F' && len(quantization_level) >= 3: // ex: F16, F32
 		return quantization_level[0:3]
-	case 'B': // ex: BF16
+	case quantization_level[0] == 'B' && len(quantization_level) >= 4: // ex: BF16
 		return quantization_level[1:4]
 	default:
-		fmt.Printf("NormalizeQuantizationLevel prefix not recognized: %s", quantization_level)
+		fmt.Printf("NormalizeQuantizationLevel prefix not recognized: %s\n", quantization_level)
 		return ""
 	}
 }
 
+// CheckQuantizationLevel tells if quantization_level is a target the
+// estimations know, the others would silently fall into the defaults
+func CheckQuantizationLevel(quantization_level string) error {
+	level := strings.ToUpper(quantization_level)
+	if len(level) >= 2 && level[0] == 'Q' || len(level) >= 3 && level[0] == 'F' || len(level) >= 4 && level[0] == 'B' {
+		switch NormalizeQuantizationLevel(level) {
+		case "Q4", "Q5", "Q8", "F16", "F32":
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unsupported quantization level %q, use q4_*, q5_*, q8_0, f16, bf16 or f32", quantization_level)
+}
+
 func FormatParamCount(parameter_count int64) string {
 	switch {
 	case parameter_count >= 1_000_000_000:
@@ -0,0 +1,60 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeQuantizationLevel(t *testing.T) {
+	tests := []struct {
+		level string
+		want  string
+	}{
+		{level: "Q4_K_M", want: "Q4"},
+		{level: "F16", want: "F16"},
+		{level: "BF16", want: "F16"},
+		{level: "Q", want: ""},
+		{level: "F", want: ""},
+		{level: "BF", want: ""},
+		{level: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.level, func(t *testing.T) {
+			assert.Equal(t, tt.want, NormalizeQuantizationLevel(tt.level))
+		})
+	}
+}
+
+func TestCheckQuantizationLevel(t *testing.T) {
+	tests := []struct {
+		level   string
+		wantErr bool
+	}{
+		{level: "q4_K_M"},
+		{level: "q5_0"},
+		{level: "q8_0"},
+		{level: "f16"},
+		{level: "bf16"},
+		{level: "f32"},
+		{level: "q6_K", wantErr: true},
+		{level: "q3_K_M", wantErr: true},
+		{level: "q2_K", wantErr: true},
+		{level: "q", wantErr: true},
+		{level: "f", wantErr: true},
+		{level: "int8", wantErr: true},
+		{level: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.level, func(t *testing.T) {
+			err := CheckQuantizationLevel(tt.level)
+			if tt.wantErr {
+				assert.ErrorContains(t, err, "unsupported quantization level")
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
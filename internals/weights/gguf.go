@@ -0,0 +1,320 @@
+package weights
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+const ggufMagic = "GGUF"
+
+// ggufMaxString bounds the strings read from a file, chat templates are the
+// longest and are far smaller, a larger length means the file is corrupt
+const ggufMaxString = 16 << 20
+
+// gguf metadata value types
+const (
+	ggufTypeUint8 uint32 = iota
+	ggufTypeInt8
+	ggufTypeUint16
+	ggufTypeInt16
+	ggufTypeUint32
+	ggufTypeInt32
+	ggufTypeFloat32
+	ggufTypeBool
+	ggufTypeString
+	ggufTypeArray
+	ggufTypeUint64
+	ggufTypeInt64
+	ggufTypeFloat64
+)
+
+// ggufFileTypes maps `general.file_type` to the quantization level names
+// used by Ollama
+var ggufFileTypes = map[uint32]string{
+	0:  "F32",
+	1:  "F16",
+	2:  "Q4_0",
+	3:  "Q4_1",
+	7:  "Q8_0",
+	8:  "Q5_0",
+	9:  "Q5_1",
+	10: "Q2_K",
+	11: "Q3_K_S",
+	12: "Q3_K_M",
+	13: "Q3_K_L",
+	14: "Q4_K_S",
+	15: "Q4_K_M",
+	16: "Q5_K_S",
+	17: "Q5_K_M",
+	18: "Q6_K",
+	32: "BF16",
+}
+
+type ggufReader struct {
+	r       *bufio.Reader
+	version uint32
+}
+
+// ReadGGUF reads the metadata and tensor infos of a GGUF file
+func ReadGGUF(path string) (*Header, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %+v", path, err)
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %+v", path, err)
+	}
+
+	h, err := readGGUF(f)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %+v", path, err)
+	}
+	h.SizeBytes = info.Size()
+
+	return h, nil
+}
+
+func readGGUF(r io.Reader) (*Header, error) {
+	var (
+		g = &ggufReader{r: bufio.NewReader(r)}
+		h = &Header{
+			Format:   FormatGGUF,
+			Metadata: map[string]any{},
+		}
+		magic       = make([]byte, 4)
+		tensorCount uint64
+		kvCount     uint64
+	)
+
+	if _, err := io.ReadFull(g.r, magic); err != nil {
+		return nil, fmt.Errorf("reading magic: %+v", err)
+	}
+
+	if string(magic) != ggufMagic {
+		return nil, fmt.Errorf("invalid magic %q", magic)
+	}
+
+	if err := g.read(&g.version); err != nil {
+		return nil, fmt.Errorf("reading version: %+v", err)
+	}
+
+	if g.version < 2 {
+		return nil, fmt.Errorf("unsupported GGUF version %d", g.version)
+	}
+
+	if err := g.read(&tensorCount); err != nil {
+		return nil, fmt.Errorf("reading tensor count: %+v", err)
+	}
+
+	if err := g.read(&kvCount); err != nil {
+		return nil, fmt.Errorf("reading metadata count: %+v", err)
+	}
+
+	for i := uint64(0); i < kvCount; i++ {
+		key, err := g.readString()
+		if err != nil {
+			return nil, fmt.Errorf("reading metadata key: %+v", err)
+		}
+
+		value, err := g.readValue()
+		if err != nil {
+			return nil, fmt.Errorf("reading metadata %s: %+v", key, err)
+		}
+
+		// arrays (tokens, merges, ...) are skipped, we don't need them
+		if value != nil {
+			h.Metadata[key] = value
+		}
+	}
+
+	for i := uint64(0); i < tensorCount; i++ {
+		elements, err := g.readTensorInfo()
+		if err != nil {
+			return nil, fmt.Errorf("reading tensor %d info: %+v", i, err)
+		}
+
+		h.ParameterCount += elements
+		if elements > h.LargestTensor {
+			h.LargestTensor = elements
+		}
+	}
+	h.TensorCount = int(tensorCount)
+
+	if arch, ok := h.Metadata["general.architecture"].(string); ok {
+		h.Architecture = arch
+	}
+
+	if fileType, ok := h.Metadata["general.file_type"].(uint32); ok {
+		h.QuantizationLevel = ggufFileTypes[fileType]
+	}
+
+	return h, nil
+}
+
+func (g *ggufReader) read(v any) error {
+	return binary.Read(g.r, binary.LittleEndian, v)
+}
+
+func (g *ggufReader) readString() (string, error) {
+	var length uint64
+	if err := g.read(&length); err != nil {
+		return "", err
+	}
+
+	if length > ggufMaxString {
+		return "", fmt.Errorf("string of %d bytes, the file is corrupt", length)
+	}
+
+	buf := make([]byte, length)
+	if _, err := io.ReadFull(g.r, buf); err != nil {
+		return "", err
+	}
+
+	return string(buf), nil
+}
+
+// readValue reads a typed metadata value, arrays are consumed and returned as nil
+func (g *ggufReader) readValue() (any, error) {
+	var t uint32
+	if err := g.read(&t); err != nil {
+		return nil, err
+	}
+
+	if t == ggufTypeArray {
+		return nil, g.skipArray()
+	}
+
+	return g.readScalar(t)
+}
+
+func (g *ggufReader) readScalar(t uint32) (any, error) {
+	var err error
+
+	switch t {
+	case ggufTypeUint8:
+		var v uint8
+		err = g.read(&v)
+		return v, err
+	case ggufTypeInt8:
+		var v int8
+		err = g.read(&v)
+		return v, err
+	case ggufTypeUint16:
+		var v uint16
+		err = g.read(&v)
+		return v, err
+	case ggufTypeInt16:
+		var v int16
+		err = g.read(&v)
+		return v, err
+	case ggufTypeUint32:
+		var v uint32
+		err = g.read(&v)
+		return v, err
+	case ggufTypeInt32:
+		var v int32
+		err = g.read(&v)
+		return v, err
+	case ggufTypeFloat32:
+		var v float32
+		err = g.read(&v)
+		return v, err
+	case ggufTypeBool:
+		var v uint8
+		err = g.read(&v)
+		return v != 0, err
+	case ggufTypeString:
+		return g.readString()
+	case ggufTypeUint64:
+		var v uint64
+		err = g.read(&v)
+		return v, err
+	case ggufTypeInt64:
+		var v int64
+		err = g.read(&v)
+		return v, err
+	case ggufTypeFloat64:
+		var v float64
+		err = g.read(&v)
+		return v, err
+	default:
+		return nil, fmt.Errorf("unknown metadata type %d", t)
+	}
+}
+
+func (g *ggufReader) skipArray() error {
+	var (
+		t     uint32
+		count uint64
+	)
+
+	if err := g.read(&t); err != nil {
+		return err
+	}
+
+	if err := g.read(&count); err != nil {
+		return err
+	}
+
+	for i := uint64(0); i < count; i++ {
+		if t == ggufTypeArray {
+			if err := g.skipArray(); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if _, err := g.readScalar(t); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readTensorInfo reads a tensor descriptor and returns its element count
+func (g *ggufReader) readTensorInfo() (int64, error) {
+	var (
+		dims     uint32
+		elements int64 = 1
+		ggmlType uint32
+		offset   uint64
+	)
+
+	if _, err := g.readString(); err != nil {
+		return 0, err
+	}
+
+	if err := g.read(&dims); err != nil {
+		return 0, err
+	}
+
+	for d := uint32(0); d < dims; d++ {
+		var size uint64
+		if err := g.read(&size); err != nil {
+			return 0, err
+		}
+
+		if size > math.MaxInt64/uint64(max(elements, 1)) {
+			return 0, fmt.Errorf("tensor dimension overflow")
+		}
+		elements *= int64(size)
+	}
+
+	if err := g.read(&ggmlType); err != nil {
+		return 0, err
+	}
+
+	if err := g.read(&offset); err != nil {
+		return 0, err
+	}
+
+	return elements, nil
+}
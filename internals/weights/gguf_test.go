@@ -0,0 +1,177 @@
+package weights
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type ggufTensor struct {
+	name string
+	dims []uint64
+}
+
+// ggufBuilder writes a minimal GGUF v3 header, tensor data is not needed
+type ggufBuilder struct {
+	kv      bytes.Buffer
+	kvCount uint64
+	tensors []ggufTensor
+}
+
+func (b *ggufBuilder) write(w *bytes.Buffer, v any) {
+	_ = binary.Write(w, binary.LittleEndian, v)
+}
+
+func (b *ggufBuilder) writeString(w *bytes.Buffer, s string) {
+	b.write(w, uint64(len(s)))
+	w.WriteString(s)
+}
+
+func (b *ggufBuilder) String(key, value string) *ggufBuilder {
+	b.writeString(&b.kv, key)
+	b.write(&b.kv, ggufTypeString)
+	b.writeString(&b.kv, value)
+	b.kvCount++
+	return b
+}
+
+func (b *ggufBuilder) Uint32(key string, value uint32) *ggufBuilder {
+	b.writeString(&b.kv, key)
+	b.write(&b.kv, ggufTypeUint32)
+	b.write(&b.kv, value)
+	b.kvCount++
+	return b
+}
+
+func (b *ggufBuilder) Strings(key string, values ...string) *ggufBuilder {
+	b.writeString(&b.kv, key)
+	b.write(&b.kv, ggufTypeArray)
+	b.write(&b.kv, ggufTypeString)
+	b.write(&b.kv, uint64(len(values)))
+	for _, v := range values {
+		b.writeString(&b.kv, v)
+	}
+	b.kvCount++
+	return b
+}
+
+func (b *ggufBuilder) Tensor(name string, dims ...uint64) *ggufBuilder {
+	b.tensors = append(b.tensors, ggufTensor{name: name, dims: dims})
+	return b
+}
+
+func (b *ggufBuilder) Bytes() []byte {
+	w := &bytes.Buffer{}
+	w.WriteString(ggufMagic)
+	b.write(w, uint32(3))
+	b.write(w, uint64(len(b.tensors)))
+	b.write(w, b.kvCount)
+	w.Write(b.kv.Bytes())
+	for _, t := range b.tensors {
+		b.writeString(w, t.name)
+		b.write(w, uint32(len(t.dims)))
+		for _, d := range t.dims {
+			b.write(w, d)
+		}
+		b.write(w, uint32(1))
+		b.write(w, uint64(0))
+	}
+
+	return w.Bytes()
+}
+
+func writeFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("writing %s: %+v", path, err)
+	}
+	return path
+}
+
+func TestReadGGUF(t *testing.T) {
+	tests := []struct {
+		name         string
+		data         []byte
+		want         *Header
+		wantErrorMsg string
+	}{
+		{
+			name: "llama-f16",
+			data: (&ggufBuilder{}).
+				String("general.architecture", "llama").
+				Uint32("general.file_type", 1).
+				Uint32("llama.block_count", 2).
+				Strings("tokenizer.ggml.tokens", "a", "b", "c").
+				Tensor("token_embd.weight", 64, 100).
+				Tensor("blk.0.attn_q.weight", 64, 64).
+				Tensor("blk.0.attn_norm.weight", 64).
+				Bytes(),
+			want: &Header{
+				Format:            FormatGGUF,
+				Architecture:      "llama",
+				QuantizationLevel: "F16",
+				ParameterCount:    6400 + 4096 + 64,
+				LargestTensor:     6400,
+				TensorCount:       3,
+			},
+		},
+		{
+			name:         "bad-magic",
+			data:         []byte("GGML\x03\x00\x00\x00"),
+			wantErrorMsg: "invalid magic",
+		},
+		{
+			name:         "truncated",
+			data:         []byte("GGUF\x03\x00"),
+			wantErrorMsg: "reading version",
+		},
+		{
+			name:         "corrupt-string",
+			data:         []byte("GGUF\x03\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x01\x00\x00\x00\x00\x00\x00\x00\xff\xff\xff\xff\xff\xff\xff\xff"),
+			wantErrorMsg: "the file is corrupt",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeFile(t, tt.name+".gguf", tt.data)
+
+			got, err := ReadGGUF(path)
+			if tt.wantErrorMsg != "" {
+				assert.ErrorContains(t, err, tt.wantErrorMsg)
+				return
+			}
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.want.Format, got.Format)
+				assert.Equal(t, tt.want.Architecture, got.Architecture)
+				assert.Equal(t, tt.want.QuantizationLevel, got.QuantizationLevel)
+				assert.Equal(t, tt.want.ParameterCount, got.ParameterCount)
+				assert.Equal(t, tt.want.LargestTensor, got.LargestTensor)
+				assert.Equal(t, tt.want.TensorCount, got.TensorCount)
+				assert.Equal(t, int64(len(tt.data)), got.SizeBytes)
+				assert.Equal(t, uint32(2), got.Metadata["llama.block_count"])
+				assert.Equal(t, 2, got.BlockCount())
+			}
+		})
+	}
+}
+
+func TestRead(t *testing.T) {
+	gguf := writeFile(t, "model.bin", (&ggufBuilder{}).Tensor("w", 8, 8).Bytes())
+	h, err := Read(gguf)
+	if assert.NoError(t, err) {
+		assert.Equal(t, FormatGGUF, h.Format)
+		assert.Equal(t, int64(64), h.ParameterCount)
+	}
+
+	other := writeFile(t, "model.txt", []byte("hello world"))
+	_, err = Read(other)
+	assert.ErrorContains(t, err, "neither a GGUF nor a safetensors")
+}
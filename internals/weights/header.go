@@ -0,0 +1,76 @@
+package weights
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const (
+	FormatGGUF        = "gguf"
+	FormatSafetensors = "safetensors"
+)
+
+// Header is a summary of the tensors stored in a weights file, enough to
+// size a conversion without loading the tensors themselves
+type Header struct {
+	Format            string
+	Architecture      string
+	ParameterCount    int64
+	QuantizationLevel string
+	// SizeBytes is the on-disk size of the file(s)
+	SizeBytes int64
+	// LargestTensor is the element count of the biggest tensor
+	LargestTensor int64
+	TensorCount   int
+	// Metadata holds the scalar key/values found in the header
+	Metadata map[string]any
+}
+
+// BlockCount returns the `<architecture>.block_count` from the metadata, 0
+// when it's not there
+func (h *Header) BlockCount() int {
+	switch v := h.Metadata[h.Architecture+".block_count"].(type) {
+	case uint32:
+		return int(v)
+	case int32:
+		return int(v)
+	case uint64:
+		return int(v)
+	case int64:
+		return int(v)
+	}
+	return 0
+}
+
+// Read detects the format of the file at path and reads its header. A
+// directory is read as a set of safetensors shards
+func Read(path string) (*Header, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %+v", path, err)
+	}
+
+	if info.IsDir() || strings.HasSuffix(path, ".safetensors") {
+		return ReadSafetensors(path)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %+v", path, err)
+	}
+	defer f.Close()
+
+	magic := make([]byte, 4)
+	if _, err := io.ReadFull(f, magic); err != nil {
+		return nil, fmt.Errorf("reading magic: %+v", err)
+	}
+
+	if bytes.Equal(magic, []byte(ggufMagic)) {
+		return ReadGGUF(path)
+	}
+
+	return nil, fmt.Errorf("%s is neither a GGUF nor a safetensors file", path)
+}
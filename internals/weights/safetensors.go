@@ -0,0 +1,139 @@
+package weights
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// safetensors headers are capped at 100MB by the reference implementation
+const safetensorsMaxHeader = 100 * 1024 * 1024
+
+type safetensorsTensor struct {
+	DType string  `json:"dtype"`
+	Shape []int64 `json:"shape"`
+}
+
+// safetensorsDTypes maps safetensors dtypes to the quantization level names
+// used by Ollama
+var safetensorsDTypes = map[string]string{
+	"F32":  "F32",
+	"F16":  "F16",
+	"BF16": "BF16",
+	"F64":  "F32",
+	"I8":   "Q8_0",
+	"U8":   "Q8_0",
+}
+
+// ReadSafetensors reads the header of a safetensors file. When path is a
+// directory all the `*.safetensors` shards in it are summed up
+func ReadSafetensors(path string) (*Header, error) {
+	var (
+		files  []string
+		h      = &Header{Format: FormatSafetensors, Metadata: map[string]any{}}
+		dtypes = map[string]int64{}
+	)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %+v", path, err)
+	}
+
+	if info.IsDir() {
+		if files, err = filepath.Glob(filepath.Join(path, "*.safetensors")); err != nil {
+			return nil, fmt.Errorf("listing %s: %+v", path, err)
+		}
+
+		if len(files) == 0 {
+			return nil, fmt.Errorf("no safetensors files found in %s", path)
+		}
+		sort.Strings(files)
+	} else {
+		files = []string{path}
+	}
+
+	for _, file := range files {
+		if err := readSafetensorsFile(file, h, dtypes); err != nil {
+			return nil, fmt.Errorf("reading %s: %+v", file, err)
+		}
+	}
+
+	// the dominant dtype gives the quantization level, norms and biases are
+	// usually kept at higher precision and should not count
+	var most int64
+	for dtype, elements := range dtypes {
+		if elements > most {
+			most = elements
+			h.QuantizationLevel = safetensorsDTypes[dtype]
+		}
+	}
+
+	return h, nil
+}
+
+func readSafetensorsFile(path string, h *Header, dtypes map[string]int64) error {
+	var (
+		length  uint64
+		tensors = map[string]json.RawMessage{}
+	)
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	if err := binary.Read(f, binary.LittleEndian, &length); err != nil {
+		return fmt.Errorf("reading header length: %+v", err)
+	}
+
+	if length > safetensorsMaxHeader || length > uint64(info.Size()) {
+		return fmt.Errorf("invalid header length %d", length)
+	}
+
+	if err := json.NewDecoder(io.LimitReader(f, int64(length))).Decode(&tensors); err != nil {
+		return fmt.Errorf("decoding header: %+v", err)
+	}
+
+	for name, raw := range tensors {
+		if name == "__metadata__" {
+			metadata := map[string]string{}
+			if err := json.Unmarshal(raw, &metadata); err == nil {
+				for k, v := range metadata {
+					h.Metadata[k] = v
+				}
+			}
+			continue
+		}
+
+		tensor := &safetensorsTensor{}
+		if err := json.Unmarshal(raw, tensor); err != nil {
+			return fmt.Errorf("decoding tensor %s: %+v", name, err)
+		}
+
+		var elements int64 = 1
+		for _, dim := range tensor.Shape {
+			elements *= dim
+		}
+
+		h.ParameterCount += elements
+		h.TensorCount++
+		dtypes[tensor.DType] += elements
+		if elements > h.LargestTensor {
+			h.LargestTensor = elements
+		}
+	}
+
+	h.SizeBytes += info.Size()
+
+	return nil
+}
@@ -0,0 +1,62 @@
+package weights
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func safetensorsBytes(header string) []byte {
+	w := &bytes.Buffer{}
+	_ = binary.Write(w, binary.LittleEndian, uint64(len(header)))
+	w.WriteString(header)
+	return w.Bytes()
+}
+
+func TestReadSafetensors(t *testing.T) {
+	var (
+		shard1 = safetensorsBytes(`{"__metadata__":{"format":"pt"},"embed.weight":{"dtype":"BF16","shape":[100,64],"data_offsets":[0,12800]},"norm.weight":{"dtype":"F32","shape":[64],"data_offsets":[12800,13056]}}`)
+		shard2 = safetensorsBytes(`{"lm_head.weight":{"dtype":"BF16","shape":[64,100],"data_offsets":[0,12800]}}`)
+	)
+
+	t.Run("single-file", func(t *testing.T) {
+		path := writeFile(t, "model.safetensors", shard1)
+		got, err := ReadSafetensors(path)
+		if assert.NoError(t, err) {
+			assert.Equal(t, FormatSafetensors, got.Format)
+			assert.Equal(t, "BF16", got.QuantizationLevel)
+			assert.Equal(t, int64(6464), got.ParameterCount)
+			assert.Equal(t, int64(6400), got.LargestTensor)
+			assert.Equal(t, 2, got.TensorCount)
+			assert.Equal(t, "pt", got.Metadata["format"])
+		}
+	})
+
+	t.Run("directory", func(t *testing.T) {
+		dir := t.TempDir()
+		_ = os.WriteFile(filepath.Join(dir, "model-00001-of-00002.safetensors"), shard1, 0o644)
+		_ = os.WriteFile(filepath.Join(dir, "model-00002-of-00002.safetensors"), shard2, 0o644)
+
+		got, err := Read(dir)
+		if assert.NoError(t, err) {
+			assert.Equal(t, int64(12864), got.ParameterCount)
+			assert.Equal(t, 3, got.TensorCount)
+			assert.Equal(t, int64(len(shard1)+len(shard2)), got.SizeBytes)
+		}
+	})
+
+	t.Run("empty-directory", func(t *testing.T) {
+		_, err := ReadSafetensors(t.TempDir())
+		assert.ErrorContains(t, err, "no safetensors files found")
+	})
+
+	t.Run("invalid-header-length", func(t *testing.T) {
+		path := writeFile(t, "broken.safetensors", []byte{0xff, 0xff, 0xff, 0xff, 0, 0, 0, 0, '{', '}'})
+		_, err := ReadSafetensors(path)
+		assert.ErrorContains(t, err, "invalid header length")
+	})
+}
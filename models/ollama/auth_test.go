@@ -0,0 +1,60 @@
+package ollama
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_Auth(t *testing.T) {
+	tests := []struct {
+		name              string
+		opts              []ClientOption
+		wantAuthorization string
+		wantHeaders       map[string]string
+	}{
+		{name: "none"},
+		{
+			name:              "bearer-token",
+			opts:              []ClientOption{WithBearerToken("s3cret")},
+			wantAuthorization: "Bearer s3cret",
+		},
+		{
+			name:              "basic-auth",
+			opts:              []ClientOption{WithBasicAuth("pato", "s3cret")},
+			wantAuthorization: "Basic cGF0bzpzM2NyZXQ=",
+		},
+		{
+			name:              "headers",
+			opts:              []ClientOption{WithBearerToken("s3cret"), WithHeaders(map[string]string{"x-team": "ml", "X-Trace": "on"})},
+			wantAuthorization: "Bearer s3cret",
+			wantHeaders:       map[string]string{"X-Team": "ml", "X-Trace": "on"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got http.Header
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Header.Clone()
+				_, _ = w.Write([]byte(`{"models":[]}`))
+			}))
+			defer ts.Close()
+
+			c := NewClient(ts.URL, tt.opts...)
+			defer c.Close()
+
+			if _, err := c.Tags(context.Background()); !assert.NoError(t, err) {
+				return
+			}
+
+			assert.Equal(t, tt.wantAuthorization, got.Get("Authorization"))
+			for name, value := range tt.wantHeaders {
+				assert.Equal(t, value, got.Get(name))
+			}
+		})
+	}
+}
@@ -0,0 +1,17 @@
+package ollama
+
+import "context"
+
+// Backend is a model server we can list models from and describe them,
+// the Ollama api or an OpenAI compatible one
+type Backend interface {
+	Tags(ctx context.Context) (*Tags, error)
+	Show(ctx context.Context, model_name string) (*Model, error)
+	BaseUrl() string
+	Close() error
+}
+
+var (
+	_ Backend = (*Client)(nil)
+	_ Backend = (*OpenAIBackend)(nil)
+)
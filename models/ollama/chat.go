@@ -0,0 +1,39 @@
+package ollama
+
+import "time"
+
+type ChatRequest struct {
+	Model    string         `json:"model"`
+	Messages []Message      `json:"messages"`
+	Options  map[string]any `json:"options,omitempty"`
+}
+
+// Metrics are the timings and token counts sent with the last chunk of a
+// generate or chat response
+type Metrics struct {
+	TotalDuration      time.Duration `json:"total_duration,omitempty"`
+	LoadDuration       time.Duration `json:"load_duration,omitempty"`
+	PromptEvalCount    int           `json:"prompt_eval_count,omitempty"`
+	PromptEvalDuration time.Duration `json:"prompt_eval_duration,omitempty"`
+	EvalCount          int           `json:"eval_count,omitempty"`
+	EvalDuration       time.Duration `json:"eval_duration,omitempty"`
+}
+
+// TokensPerSecond returns the generation speed, 0 if it's unknown
+func (m *Metrics) TokensPerSecond() float64 {
+	if m.EvalDuration <= 0 {
+		return 0
+	}
+	return float64(m.EvalCount) / m.EvalDuration.Seconds()
+}
+
+// ChatResponse is a chunk of the `/api/chat` stream
+type ChatResponse struct {
+	Model      string    `json:"model"`
+	CreatedAt  time.Time `json:"created_at"`
+	Message    Message   `json:"message"`
+	Done       bool      `json:"done"`
+	DoneReason string    `json:"done_reason,omitempty"`
+
+	Metrics
+}
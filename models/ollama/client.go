@@ -0,0 +1,486 @@
+package ollama
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/padiazg/ollama-tools/models/version"
+	"resty.dev/v3"
+)
+
+const (
+	DefaultBaseUrl  = "http://localhost:11434"
+	ApiPathTags     = "/api/tags"
+	ApiPathShow     = "/api/show"
+	ApiPathPs       = "/api/ps"
+	ApiPathPull     = "/api/pull"
+	ApiPathDelete   = "/api/delete"
+	ApiPathCopy     = "/api/copy"
+	ApiPathCreate   = "/api/create"
+	ApiPathBlobs    = "/api/blobs/"
+	ApiPathChat     = "/api/chat"
+	ApiPathEmbed    = "/api/embed"
+	ApiPathVersion  = "/api/version"
+	ApiPathGenerate = "/api/generate"
+)
+
+// Client talks to the Ollama api, it holds a single http client so
+// connections are reused across calls
+type Client struct {
+	baseUrl   string
+	transport http.RoundTripper
+	timeout   time.Duration
+	retry     RetryPolicy
+	logger    io.Writer
+	token     string
+	username  string
+	password  string
+	headers   map[string]string
+	rc        *resty.Client
+
+	mu            sync.Mutex
+	serverVersion *version.VersionInfo
+}
+
+type ClientOption func(*Client)
+
+// WithTransport sets the http.RoundTripper used for every request
+func WithTransport(transport http.RoundTripper) ClientOption {
+	return func(c *Client) {
+		c.transport = transport
+	}
+}
+
+// WithTimeout sets the time limit for every attempt of a non streaming
+// request, streaming requests are only bound by their context
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithLogger sets where retries are reported, nothing is logged without it
+func WithLogger(w io.Writer) ClientOption {
+	return func(c *Client) {
+		c.logger = w
+	}
+}
+
+// WithBearerToken sends the token in the Authorization header of every
+// request, for servers behind an authenticating proxy
+func WithBearerToken(token string) ClientOption {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithBasicAuth sends the credentials in the Authorization header of every
+// request
+func WithBasicAuth(username string, password string) ClientOption {
+	return func(c *Client) {
+		c.username, c.password = username, password
+	}
+}
+
+// WithHeaders adds the headers to every request
+func WithHeaders(headers map[string]string) ClientOption {
+	return func(c *Client) {
+		c.headers = headers
+	}
+}
+
+func NewClient(base_url string, opts ...ClientOption) *Client {
+	if base_url == "" {
+		base_url = DefaultBaseUrl
+	}
+
+	c := &Client{baseUrl: strings.TrimSuffix(base_url, "/")}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	// `/api/delete` takes the model name in a DELETE body
+	c.rc = resty.New().
+		SetBaseURL(c.baseUrl).
+		SetAllowMethodDeletePayload(true)
+	if c.transport != nil {
+		c.rc.SetTransport(c.transport)
+	}
+
+	if len(c.headers) > 0 {
+		c.rc.SetHeaders(c.headers)
+	}
+
+	switch {
+	case c.token != "":
+		c.rc.SetAuthToken(c.token)
+	case c.username != "":
+		c.rc.SetBasicAuth(c.username, c.password)
+	}
+
+	return c
+}
+
+func (c *Client) BaseUrl() string {
+	return c.baseUrl
+}
+
+// Close releases the idle connections
+func (c *Client) Close() error {
+	return c.rc.Close()
+}
+
+// StatusError is returned when the server answers with a non 2xx status
+type StatusError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *StatusError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("response status code: %d", e.StatusCode)
+	}
+	return fmt.Sprintf("response status code: %d: %s", e.StatusCode, e.Message)
+}
+
+// Tags lists the models available locally
+func (c *Client) Tags(ctx context.Context) (*Tags, error) {
+	tags := &Tags{}
+	if err := c.do(ctx, http.MethodGet, ApiPathTags, nil, tags); err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+type ShowRequest struct {
+	Model string `json:"model"`
+}
+
+// Show returns the details and metadata of a model
+func (c *Client) Show(ctx context.Context, model_name string) (*Model, error) {
+	model := &Model{}
+	if err := c.do(ctx, http.MethodPost, ApiPathShow, &ShowRequest{Model: model_name}, model); err != nil {
+		return nil, err
+	}
+
+	return model, nil
+}
+
+// Ps lists the models currently loaded in memory
+func (c *Client) Ps(ctx context.Context) (*ProcessList, error) {
+	list := &ProcessList{}
+	if err := c.do(ctx, http.MethodGet, ApiPathPs, nil, list); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// Pull downloads a model, fn is called for every progress update
+func (c *Client) Pull(ctx context.Context, req *PullRequest, fn func(*ProgressResponse) error) error {
+	return stream(ctx, c, http.MethodPost, ApiPathPull, req, fn)
+}
+
+type DeleteRequest struct {
+	Model string `json:"model"`
+}
+
+// Delete removes a model and the blobs no other model uses
+func (c *Client) Delete(ctx context.Context, model_name string) error {
+	return c.do(ctx, http.MethodDelete, ApiPathDelete, &DeleteRequest{Model: model_name}, nil)
+}
+
+type CopyRequest struct {
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+}
+
+// Copy creates destination as another name for source, sharing its blobs
+func (c *Client) Copy(ctx context.Context, source string, destination string) error {
+	return c.do(ctx, http.MethodPost, ApiPathCopy, &CopyRequest{Source: source, Destination: destination}, nil)
+}
+
+// Create builds a model, fn is called for every status update
+func (c *Client) Create(ctx context.Context, req *CreateRequest, fn func(*ProgressResponse) error) error {
+	return stream(ctx, c, http.MethodPost, ApiPathCreate, req, fn)
+}
+
+// BlobExists checks if the server already has the blob, digest is
+// `sha256:<hex>`
+func (c *Client) BlobExists(ctx context.Context, digest string) (bool, error) {
+	err := c.do(ctx, http.MethodHead, ApiPathBlobs+digest, nil, nil)
+	var se *StatusError
+	if errors.As(err, &se) && se.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// CreateBlob uploads r as the blob with the given digest, the upload is
+// only bound by ctx as it can take long
+func (c *Client) CreateBlob(ctx context.Context, digest string, r io.Reader) error {
+	res, err := c.send(ctx, http.MethodPost, ApiPathBlobs+digest, r)
+	if err != nil {
+		return err
+	}
+
+	return res.Body.Close()
+}
+
+// Chat sends the conversation, fn is called for every chunk of the reply
+func (c *Client) Chat(ctx context.Context, req *ChatRequest, fn func(*ChatResponse) error) error {
+	return stream(ctx, c, http.MethodPost, ApiPathChat, req, fn)
+}
+
+// Embed returns an embedding for every input
+func (c *Client) Embed(ctx context.Context, req *EmbedRequest) (*EmbedResponse, error) {
+	res := &EmbedResponse{}
+	if err := c.do(ctx, http.MethodPost, ApiPathEmbed, req, res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// Generate sends a non streaming generate request, it's used to load and
+// unload models. Loading can take long, so only ctx bounds it
+func (c *Client) Generate(ctx context.Context, req *GenerateRequest) (*GenerateResponse, error) {
+	stream_response := false
+	req.Stream = &stream_response
+
+	res := &GenerateResponse{}
+	err := stream(ctx, c, http.MethodPost, ApiPathGenerate, req, func(r *GenerateResponse) error {
+		*res = *r
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+type VersionResponse struct {
+	Version string `json:"version"`
+}
+
+// Version returns the version of the server
+func (c *Client) Version(ctx context.Context) (string, error) {
+	res := &VersionResponse{}
+	if err := c.do(ctx, http.MethodGet, ApiPathVersion, nil, res); err != nil {
+		return "", err
+	}
+
+	return res.Version, nil
+}
+
+// ServerVersion returns the parsed server version, it's asked once and
+// kept for the life of the client
+func (c *Client) ServerVersion(ctx context.Context) (*version.VersionInfo, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.serverVersion != nil {
+		return c.serverVersion, nil
+	}
+
+	v, err := c.Version(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c.serverVersion = version.Parse(v)
+
+	return c.serverVersion, nil
+}
+
+// do sends body as json and decodes the response into result, if given.
+// Idempotent calls are retried as the retry policy says
+func (c *Client) do(ctx context.Context, method string, path string, body any, result any) error {
+	if c.retry.MaxElapsed > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.retry.MaxElapsed)
+		defer cancel()
+	}
+
+	retries := 0
+	if idempotent(method, path) {
+		retries = c.retry.Retries
+	}
+
+	for attempt := 1; ; attempt++ {
+		retry, err := c.doOnce(ctx, method, path, body, result)
+		if err == nil {
+			return nil
+		}
+
+		if !retry || attempt > retries || ctx.Err() != nil {
+			switch {
+			case attempt > 1 && ctx.Err() != nil:
+				// the deadline ran out during the request instead of the wait
+				return fmt.Errorf("%w, gave up after %d attempts: %+v", err, attempt, ctx.Err())
+			case attempt > 1:
+				return fmt.Errorf("%w, after %d attempts", err, attempt)
+			}
+			return err
+		}
+
+		wait := c.retry.backoff(attempt)
+		c.logf("%s %s failed: %+v, retrying in %s (%d of %d)", method, path, err, wait.Round(time.Millisecond), attempt, retries)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w, gave up after %d attempts: %+v", err, attempt, ctx.Err())
+		case <-time.After(wait):
+		}
+	}
+}
+
+// doOnce makes a single attempt bound by the client timeout, it tells if
+// the error is worth retrying. Decoding errors aren't
+func (c *Client) doOnce(ctx context.Context, method string, path string, body any, result any) (bool, error) {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	res, err := c.send(ctx, method, path, body)
+	if err != nil {
+		return retryable(err), err
+	}
+	defer res.Body.Close()
+
+	if result == nil {
+		_, _ = io.Copy(io.Discard, res.Body)
+		return false, nil
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(result); err != nil {
+		return false, fmt.Errorf("decoding response: %+v", err)
+	}
+
+	return false, nil
+}
+
+// streamMaxLine is the longest NDJSON line we accept
+const streamMaxLine = 8 * 1024 * 1024
+
+// stream sends body as json and calls fn for every line of the NDJSON
+// response. A line with an `error` field ends the stream with that error.
+// The client timeout doesn't apply, streams are only bound by ctx
+func stream[T any](ctx context.Context, c *Client, method string, path string, body any, fn func(*T) error) error {
+	res, err := c.send(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	scanner := bufio.NewScanner(res.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), streamMaxLine)
+
+	for scanner.Scan() {
+		var (
+			line   = scanner.Bytes()
+			item   = new(T)
+			failed struct {
+				Error string `json:"error"`
+			}
+		)
+
+		if len(line) == 0 {
+			continue
+		}
+
+		if err := json.Unmarshal(line, &failed); err == nil && failed.Error != "" {
+			return fmt.Errorf("%s", failed.Error)
+		}
+
+		if err := json.Unmarshal(line, item); err != nil {
+			return fmt.Errorf("decoding stream: %+v", err)
+		}
+
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("reading stream: %+v", err)
+	}
+
+	return nil
+}
+
+// send executes the request and checks the response status, the caller
+// must close the returned body
+func (c *Client) send(ctx context.Context, method string, path string, body any) (*http.Response, error) {
+	req := c.rc.R().
+		SetContext(ctx).
+		SetDoNotParseResponse(true).
+		SetHeader("Accept", "application/json")
+
+	if body != nil {
+		req.SetBody(body)
+	}
+
+	res, err := req.Execute(method, path)
+	if err != nil {
+		if te := tlsError(err); te != nil {
+			return nil, fmt.Errorf("requesting %s: %w", path, te)
+		}
+		return nil, fmt.Errorf("requesting %s: %w", path, err)
+	}
+
+	raw := res.RawResponse
+	if raw.Body == nil {
+		raw.Body = http.NoBody
+	}
+
+	if !res.IsSuccess() {
+		defer raw.Body.Close()
+		return nil, newStatusError(raw)
+	}
+
+	return raw, nil
+}
+
+// Unreachable tells if err is a failure to reach the server, like a refused
+// connection or a bad certificate, rather than an error it answered with
+func Unreachable(err error) bool {
+	var (
+		ue *url.Error
+		te *TLSError
+	)
+
+	return errors.As(err, &ue) || errors.As(err, &te)
+}
+
+func newStatusError(res *http.Response) *StatusError {
+	var (
+		e       = &StatusError{StatusCode: res.StatusCode}
+		payload struct {
+			Error string `json:"error"`
+		}
+	)
+
+	data, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+	if err := json.Unmarshal(data, &payload); err == nil {
+		e.Message = payload.Error
+	} else {
+		e.Message = strings.TrimSpace(string(data))
+	}
+
+	return e
+}
@@ -0,0 +1,97 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_Tags(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, ApiPathTags, r.URL.Path)
+		_, _ = w.Write([]byte(`{"models":[{"name":"phi4:latest","size":9053116391}]}`))
+	}))
+	defer ts.Close()
+
+	c := NewClient(ts.URL + "/")
+	defer c.Close()
+
+	got, err := c.Tags(context.Background())
+	if assert.NoError(t, err) && assert.Len(t, got.Models, 1) {
+		assert.Equal(t, "phi4:latest", got.Models[0].Name)
+	}
+}
+
+func TestClient_Show(t *testing.T) {
+	var (
+		connections = 0
+		ts          = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			req := &ShowRequest{}
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(req))
+			if req.Model == "missing" {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"error":"model 'missing' not found"}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"details":{"family":"llama","parent_model":` + strconv.Quote(req.Model) + `},"model_info":{"llama.context_length":8192}}`))
+		}))
+	)
+
+	ts.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			connections++
+		}
+	}
+	ts.Start()
+	defer ts.Close()
+
+	c := NewClient(ts.URL)
+	defer c.Close()
+
+	// names are sent as json, quotes must survive the round trip
+	got, err := c.Show(context.Background(), `we"ird`)
+	if assert.NoError(t, err) {
+		assert.Equal(t, `we"ird`, got.Details.ParentModel)
+		assert.Equal(t, 8192, got.ModelInfo.ContextLength)
+	}
+
+	_, err = c.Show(context.Background(), "llama3:latest")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, connections, "connections should be reused")
+
+	_, err = c.Show(context.Background(), "missing")
+	var se *StatusError
+	if assert.ErrorAs(t, err, &se) {
+		assert.Equal(t, http.StatusNotFound, se.StatusCode)
+		assert.Equal(t, "model 'missing' not found", se.Message)
+	}
+}
+
+func TestClient_Timeout(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer ts.Close()
+
+	c := NewClient(ts.URL, WithTimeout(50*time.Millisecond))
+	defer c.Close()
+
+	_, err := c.Tags(context.Background())
+	assert.ErrorContains(t, err, "deadline exceeded")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = NewClient(ts.URL).Tags(ctx)
+	assert.ErrorContains(t, err, "canceled")
+}
@@ -0,0 +1,16 @@
+package ollama
+
+// CreateRequest builds a model, the Modelfile commands map to its fields
+// and local files are referenced by the digest of their uploaded blob
+type CreateRequest struct {
+	Model      string            `json:"model"`
+	From       string            `json:"from,omitempty"`
+	Files      map[string]string `json:"files,omitempty"`
+	Adapters   map[string]string `json:"adapters,omitempty"`
+	Template   string            `json:"template,omitempty"`
+	License    []string          `json:"license,omitempty"`
+	System     string            `json:"system,omitempty"`
+	Parameters map[string]any    `json:"parameters,omitempty"`
+	Messages   []Message         `json:"messages,omitempty"`
+	Quantize   string            `json:"quantize,omitempty"`
+}
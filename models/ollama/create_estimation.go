@@ -0,0 +1,8 @@
+package ollama
+
+type CreateEstimation struct {
+	SourceSize float64
+	OutputSize float64
+	PeakDisk   float64
+	PeakRAM    float64
+}
@@ -0,0 +1,46 @@
+package ollama
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/padiazg/ollama-tools/models/version"
+)
+
+// Feature is a part of the api that only exists since a given server
+// version. All the versions we depend on are kept here
+type Feature struct {
+	Name  string
+	Since string
+}
+
+var (
+	FeatureEmbed           = &Feature{Name: "/api/embed", Since: "0.3.0"}
+	FeaturePsContextLength = &Feature{Name: "context_length in /api/ps", Since: "0.11.0"}
+)
+
+// VersionError is returned when the server is older than a feature needs
+type VersionError struct {
+	Feature *Feature
+	Server  string
+}
+
+func (e *VersionError) Error() string {
+	return fmt.Sprintf("%s requires Ollama >= %s, the server is %s", e.Feature.Name, e.Feature.Since, e.Server)
+}
+
+// Require returns a *VersionError when the server is older than f needs.
+// When the version can't be known, or it's a development build, the
+// server is given the benefit of the doubt
+func (c *Client) Require(ctx context.Context, f *Feature) error {
+	v, err := c.ServerVersion(ctx)
+	if err != nil || v.IsZero() {
+		return nil
+	}
+
+	if !v.AtLeast(version.Parse(f.Since)) {
+		return &VersionError{Feature: f, Server: v.Version}
+	}
+
+	return nil
+}
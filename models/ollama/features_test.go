@@ -0,0 +1,58 @@
+package ollama
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_Require(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		version string
+		feature *Feature
+		wantErr string
+	}{
+		{name: "newer", status: http.StatusOK, version: "0.3.1", feature: FeatureEmbed},
+		{name: "same", status: http.StatusOK, version: "0.3.0", feature: FeatureEmbed},
+		{name: "older", status: http.StatusOK, version: "0.2.8", feature: FeatureEmbed, wantErr: "/api/embed requires Ollama >= 0.3.0, the server is 0.2.8"},
+		{name: "pre-release", status: http.StatusOK, version: "0.11.0-rc1", feature: FeaturePsContextLength, wantErr: "requires Ollama >= 0.11.0"},
+		{name: "development-build", status: http.StatusOK, version: "0.0.0", feature: FeatureEmbed},
+		{name: "unknown", status: http.StatusNotFound, feature: FeatureEmbed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests := 0
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, ApiPathVersion, r.URL.Path)
+				requests++
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(`{"version":"` + tt.version + `"}`))
+			}))
+			defer ts.Close()
+
+			c := NewClient(ts.URL)
+			defer c.Close()
+
+			err := c.Require(context.Background(), tt.feature)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				assert.IsType(t, &VersionError{}, err)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			// asked again from the cached version
+			assert.Equal(t, err, c.Require(context.Background(), tt.feature))
+
+			if tt.status == http.StatusOK {
+				assert.Equal(t, 1, requests, "the version is asked once")
+			}
+		})
+	}
+}
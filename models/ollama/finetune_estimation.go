@@ -0,0 +1,13 @@
+package ollama
+
+type FinetuneEstimation struct {
+	AdapterParameters int64
+	BaseModelSize     float64
+	AdapterWeights    float64
+	Gradients         float64
+	OptimizerState    float64
+	Activations       float64
+	GPURAM            float64
+	// AdapterSize is the F16 ADAPTER layer as loaded by Ollama
+	AdapterSize float64
+}
@@ -0,0 +1,35 @@
+package ollama
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// FlexInt is an integer that some architectures report per layer as an
+// array (ex: head_count_kv on OpenELM), in that case the biggest value is kept
+type FlexInt int
+
+func (f *FlexInt) UnmarshalJSON(raw []byte) error {
+	var (
+		single int
+		list   []int
+	)
+
+	if err := json.Unmarshal(raw, &single); err == nil {
+		*f = FlexInt(single)
+		return nil
+	}
+
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return fmt.Errorf("expected a number or a list of numbers: %s", raw)
+	}
+
+	*f = 0
+	for _, v := range list {
+		if FlexInt(v) > *f {
+			*f = FlexInt(v)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,25 @@
+package ollama
+
+import "time"
+
+// GenerateRequest is an `/api/generate` request. Without a prompt the model
+// is only loaded, or unloaded when KeepAlive is 0
+type GenerateRequest struct {
+	Model  string `json:"model"`
+	Prompt string `json:"prompt,omitempty"`
+	Stream *bool  `json:"stream,omitempty"`
+	// KeepAlive is a number of seconds or a duration string like `10m`,
+	// negative values keep the model loaded until the server stops
+	KeepAlive any            `json:"keep_alive,omitempty"`
+	Options   map[string]any `json:"options,omitempty"`
+}
+
+type GenerateResponse struct {
+	Model      string    `json:"model"`
+	CreatedAt  time.Time `json:"created_at"`
+	Response   string    `json:"response"`
+	Done       bool      `json:"done"`
+	DoneReason string    `json:"done_reason,omitempty"`
+
+	Metrics
+}
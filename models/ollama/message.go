@@ -0,0 +1,6 @@
+package ollama
+
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
@@ -7,12 +7,29 @@ import (
 )
 
 type Model struct {
-	Details   ModelDetails `json:"details"`
-	ModelInfo ModelInfo    `json:"model_info"`
+	Modelfile    string       `json:"modelfile"`
+	Details      ModelDetails `json:"details"`
+	ModelInfo    ModelInfo    `json:"model_info"`
+	Capabilities []string     `json:"capabilities,omitempty"`
+	// NumCtx is the context the server runs the model with, only backends
+	// that report it fill it
+	NumCtx int `json:"-"`
+}
+
+// HasCapability tells if the model has a capability like `completion`,
+// `embedding` or `tools`, servers before 0.6.4 don't list them
+func (m *Model) HasCapability(capability string) bool {
+	for _, c := range m.Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
 }
 
 // UnmarshalJSON will try to normalize field names before
-// unmarshaling
+// unmarshaling. Models without a family nor an architecture are
+// unmarshaled as they are, leaving the family fields empty
 func (m *Model) UnmarshalJSON(raw []byte) error {
 	var (
 		data []byte
@@ -20,7 +37,7 @@ func (m *Model) UnmarshalJSON(raw []byte) error {
 	)
 
 	if data, err = replaceFamilyFields(raw); err != nil {
-		return fmt.Errorf("normalizig fields: %+v", err)
+		data = raw
 	}
 
 	type Alias Model
@@ -29,21 +46,39 @@ func (m *Model) UnmarshalJSON(raw []byte) error {
 	return json.Unmarshal([]byte(data), &temp)
 }
 
+// familyFields are the `model_info` fields prefixed with the family name
+// that we need, `head_count_kv` goes before `head_count` so it's not cut short
+const familyFields = `context_length|embedding_length|block_count|feed_forward_length|` +
+	`attention\.head_count_kv|attention\.head_count|attention\.key_length`
+
 // replaceFamilyFields will raplace the family name with a plain `model`
-// at the beggining of some fields
+// at the beggining of some fields. If the details have no family the
+// `general.architecture` is used instead
 func replaceFamilyFields(raw []byte) ([]byte, error) {
-	familySearch := regexp.
-		MustCompile(`(?U)"family":\s?"(.*)",`).
-		FindAllStringSubmatch(string(raw), -1)
+	family := firstMatch(`(?U)"family":\s?"(.*)",`, raw)
+	if family == "" {
+		family = firstMatch(`(?U)"general\.architecture":\s?"(.*)"`, raw)
+	}
 
-	if len(familySearch) < 1 {
+	if family == "" {
 		return nil, fmt.Errorf("no family found")
 	}
 
-	family := familySearch[0][1]
+	family = regexp.QuoteMeta(family)
 	data := regexp.
-		MustCompile(fmt.Sprintf(`(?m)(?U)%s\.(context_length|embedding_length)`, family)).
+		MustCompile(fmt.Sprintf(`(?m)(?U)%s\.(%s)`, family, familyFields)).
 		ReplaceAllString(string(raw), "model.$1")
 
 	return []byte(data), nil
 }
+
+// firstMatch returns the first non empty submatch of expr in raw
+func firstMatch(expr string, raw []byte) string {
+	for _, match := range regexp.MustCompile(expr).FindAllStringSubmatch(string(raw), -1) {
+		if match[1] != "" {
+			return match[1]
+		}
+	}
+
+	return ""
+}
@@ -1,8 +1,62 @@
 package ollama
 
 type ModelInfo struct {
-	Type            string `json:"general.type"`
-	ParameterCount  int64  `json:"general.parameter_count"`
-	ContextLength   int    `json:"model.context_length"`
-	EmbeddingLength int    `json:"model.embedding_length"`
+	Type              string  `json:"general.type"`
+	ParameterCount    int64   `json:"general.parameter_count"`
+	ContextLength     int     `json:"model.context_length"`
+	EmbeddingLength   int     `json:"model.embedding_length"`
+	BlockCount        int     `json:"model.block_count"`
+	FeedForwardLength FlexInt `json:"model.feed_forward_length"`
+	HeadCount         FlexInt `json:"model.attention.head_count"`
+	HeadCountKV       FlexInt `json:"model.attention.head_count_kv"`
+	KeyLength         int     `json:"model.attention.key_length"`
+	// Inferred lists the fields filled from the architectures database
+	// instead of being reported by the server
+	Inferred []string `json:"-"`
+}
+
+// ModelInfo fields that can be inferred
+const (
+	FieldParameterCount    = "parameter_count"
+	FieldContextLength     = "context_length"
+	FieldEmbeddingLength   = "embedding_length"
+	FieldBlockCount        = "block_count"
+	FieldFeedForwardLength = "feed_forward_length"
+	FieldHeadCount         = "head_count"
+	FieldHeadCountKV       = "head_count_kv"
+	FieldKeyLength         = "key_length"
+)
+
+// IsInferred tells if field was inferred rather than reported
+func (m *ModelInfo) IsInferred(field string) bool {
+	for _, f := range m.Inferred {
+		if f == field {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HeadDim returns the size of each attention head
+func (m *ModelInfo) HeadDim() int {
+	if m.KeyLength > 0 {
+		return m.KeyLength
+	}
+
+	if m.HeadCount > 0 {
+		return m.EmbeddingLength / int(m.HeadCount)
+	}
+
+	return 0
+}
+
+// KVHeads returns the number of key/value heads, models without grouped
+// query attention use one per attention head
+func (m *ModelInfo) KVHeads() int {
+	if m.HeadCountKV > 0 {
+		return int(m.HeadCountKV)
+	}
+
+	return int(m.HeadCount)
 }
@@ -0,0 +1,134 @@
+// Package ollamatest is a fake Ollama server for tests and CI, driven by a
+// YAML fixture of models with canned metadata, streamed replies, latencies
+// and injected errors
+package ollamatest
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed default.yaml
+var defaultFixture []byte
+
+// Fixture is what the fake server knows. Latency delays every response and
+// ChunkLatency every line of a streamed one
+type Fixture struct {
+	Version      string        `yaml:"version"`
+	Latency      time.Duration `yaml:"latency"`
+	ChunkLatency time.Duration `yaml:"chunk_latency"`
+	Models       []*Model      `yaml:"models"`
+	Errors       []*Fault      `yaml:"errors"`
+}
+
+// Model is a model of the fixture. `/api/show` answers with Show when
+// it's given, or ShowFile read relative to the fixture, otherwise with a
+// body built from the other fields. Response is the text generate and chat
+// stream back, a word per chunk. NumCtx is the num_ctx PARAMETER of the
+// model, the context it's loaded with when requests don't set one
+type Model struct {
+	Name              string         `yaml:"name"`
+	Digest            string         `yaml:"digest"`
+	Size              int64          `yaml:"size"`
+	ModifiedAt        string         `yaml:"modified_at"`
+	Family            string         `yaml:"family"`
+	ParameterSize     string         `yaml:"parameter_size"`
+	QuantizationLevel string         `yaml:"quantization_level"`
+	ParameterCount    int64          `yaml:"parameter_count"`
+	ContextLength     int            `yaml:"context_length"`
+	NumCtx            int            `yaml:"num_ctx"`
+	EmbeddingLength   int            `yaml:"embedding_length"`
+	BlockCount        int            `yaml:"block_count"`
+	HeadCount         int            `yaml:"head_count"`
+	HeadCountKV       int            `yaml:"head_count_kv"`
+	Capabilities      []string       `yaml:"capabilities"`
+	Show              map[string]any `yaml:"show"`
+	ShowFile          string         `yaml:"show_file"`
+	Loaded            bool           `yaml:"loaded"`
+	SizeVRAM          int64          `yaml:"size_vram"`
+	Response          string         `yaml:"response"`
+	Latency           time.Duration  `yaml:"latency"`
+	// Remote models aren't listed until they're pulled
+	Remote bool `yaml:"remote"`
+}
+
+// Fault makes the requests to Path, for Model when it's given, fail with
+// Status and Message. With Times only that many requests fail, so retries
+// can succeed. With Stream the failure comes in the stream after the first
+// line, like a pull that breaks halfway
+type Fault struct {
+	Path    string `yaml:"path"`
+	Model   string `yaml:"model"`
+	Status  int    `yaml:"status"`
+	Message string `yaml:"message"`
+	Times   int    `yaml:"times"`
+	Stream  bool   `yaml:"stream"`
+}
+
+// DefaultFixture returns the fixture used when none is given: a chat model,
+// a second one loaded in memory and an embedding model
+func DefaultFixture() *Fixture {
+	f, err := ParseFixture(defaultFixture, "")
+	if err != nil {
+		panic(fmt.Sprintf("default fixture: %+v", err))
+	}
+
+	return f
+}
+
+// LoadFixture reads a fixture file
+func LoadFixture(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading fixture: %+v", err)
+	}
+
+	return ParseFixture(data, filepath.Dir(path))
+}
+
+// ParseFixture decodes a fixture, dir is where ShowFile paths start from
+func ParseFixture(data []byte, dir string) (*Fixture, error) {
+	f := &Fixture{}
+	if err := yaml.Unmarshal(data, f); err != nil {
+		return nil, fmt.Errorf("decoding fixture: %+v", err)
+	}
+
+	for _, m := range f.Models {
+		if m.Name == "" {
+			return nil, fmt.Errorf("fixture model without a name")
+		}
+		m.Name = withTag(m.Name)
+
+		if m.ShowFile != "" && !filepath.IsAbs(m.ShowFile) {
+			m.ShowFile = filepath.Join(dir, m.ShowFile)
+		}
+	}
+
+	for _, e := range f.Errors {
+		if e.Path == "" {
+			return nil, fmt.Errorf("fixture error without a path")
+		}
+		if e.Status == 0 {
+			e.Status = 500
+		}
+		if e.Model != "" {
+			e.Model = withTag(e.Model)
+		}
+	}
+
+	return f, nil
+}
+
+// withTag adds `:latest` to names without a tag, like the ollama cli
+func withTag(name string) string {
+	if !strings.Contains(name, ":") {
+		return name + ":latest"
+	}
+	return name
+}
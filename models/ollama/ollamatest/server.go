@@ -0,0 +1,718 @@
+package ollamatest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/padiazg/ollama-tools/models/ollama"
+)
+
+// defaultResponse is what models without a Response reply
+const defaultResponse = "This is a mock response."
+
+// defaultDimensions is the length of the embeddings of models without an
+// EmbeddingLength
+const defaultDimensions = 4
+
+// defaultNumCtx is the context Ollama loads models with when neither the
+// request nor the model set num_ctx
+const defaultNumCtx = 4096
+
+// Server is an http.Handler that answers like Ollama from a fixture. It
+// keeps which models are loaded, with which num_ctx, pulled or deleted, and
+// counts the calls
+type Server struct {
+	fixture *Fixture
+
+	mu       sync.Mutex
+	models   map[string]*Model
+	loaded   map[string]time.Time
+	contexts map[string]int
+	removed  map[string]bool
+	faults   map[*Fault]int
+	calls    map[string]int
+}
+
+// New returns a server for the fixture, nil is the default one
+func New(f *Fixture) *Server {
+	if f == nil {
+		f = DefaultFixture()
+	}
+
+	s := &Server{
+		fixture:  f,
+		models:   map[string]*Model{},
+		loaded:   map[string]time.Time{},
+		contexts: map[string]int{},
+		removed:  map[string]bool{},
+		faults:   map[*Fault]int{},
+		calls:    map[string]int{},
+	}
+
+	// fixtures built in code get the defaults ParseFixture would set
+	for _, e := range f.Errors {
+		if e.Status == 0 {
+			e.Status = 500
+		}
+		if e.Model != "" {
+			e.Model = withTag(e.Model)
+		}
+	}
+
+	for _, m := range f.Models {
+		m.Name = withTag(m.Name)
+		s.models[m.Name] = m
+		if m.Loaded {
+			s.loaded[m.Name] = time.Now().Add(5 * time.Minute)
+		}
+		if m.Remote {
+			s.removed[m.Name] = true
+		}
+	}
+
+	return s
+}
+
+// Start serves on a random local port, the server must be closed
+func (s *Server) Start() *httptest.Server {
+	return httptest.NewServer(s)
+}
+
+// Transport answers the requests in process, without a listener. The
+// response is only returned once the handler is done, streams included
+func (s *Server) Transport() http.RoundTripper {
+	return roundTripper(func(r *http.Request) (*http.Response, error) {
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, r)
+
+		res := w.Result()
+		res.Request = r
+		return res, nil
+	})
+}
+
+type roundTripper func(r *http.Request) (*http.Response, error)
+
+func (fn roundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	return fn(r)
+}
+
+// Calls returns how many requests path got
+func (s *Server) Calls(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.calls[path]
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var (
+		ctx  = r.Context()
+		body = map[string]any{}
+	)
+
+	s.mu.Lock()
+	s.calls[r.URL.Path]++
+	s.mu.Unlock()
+
+	if r.Body != nil && r.ContentLength != 0 {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+	}
+
+	name, _ := body["model"].(string)
+	if name != "" {
+		name = withTag(name)
+	}
+
+	if !sleep(ctx, s.fixture.Latency) {
+		return
+	}
+
+	if m := s.model(name); m != nil && !sleep(ctx, m.Latency) {
+		return
+	}
+
+	fault := s.fault(r.URL.Path, name)
+	if fault != nil && !fault.Stream {
+		writeError(w, fault.Status, fault.Message)
+		return
+	}
+
+	switch r.URL.Path {
+	case ollama.ApiPathVersion:
+		version := s.fixture.Version
+		if version == "" {
+			version = "0.0.0"
+		}
+		writeJSON(w, map[string]string{"version": version})
+	case ollama.ApiPathTags:
+		s.tags(w)
+	case ollama.ApiPathShow:
+		s.show(w, name)
+	case ollama.ApiPathPs:
+		s.ps(w)
+	case ollama.ApiPathGenerate:
+		s.generate(w, r, name, body, fault)
+	case ollama.ApiPathChat:
+		s.chat(w, r, name, body, fault)
+	case ollama.ApiPathEmbed:
+		s.embed(w, name, body)
+	case ollama.ApiPathPull:
+		s.pull(w, r, name, body, fault)
+	case ollama.ApiPathDelete:
+		s.delete(w, name)
+	case ollama.ApiPathCopy:
+		s.copy(w, body)
+	default:
+		writeError(w, http.StatusNotFound, "404 page not found")
+	}
+}
+
+// model returns the model by name, if it's installed
+func (s *Server) model(name string) *Model {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m, ok := s.models[name]
+	if !ok || s.removed[name] {
+		return nil
+	}
+
+	return m
+}
+
+// list returns the models of the fixture and their copies, in order
+func (s *Server) list() []*Model {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.fixture.Models)
+}
+
+// fault returns the first fault for the request that still applies
+func (s *Server) fault(path string, name string) *Fault {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, f := range s.fixture.Errors {
+		if f.Path != path || (f.Model != "" && f.Model != name) {
+			continue
+		}
+
+		if f.Times > 0 && s.faults[f] >= f.Times {
+			continue
+		}
+
+		s.faults[f]++
+		return f
+	}
+
+	return nil
+}
+
+func (s *Server) tags(w http.ResponseWriter) {
+	tags := &ollama.Tags{Models: []ollama.TagModel{}}
+	for _, m := range s.list() {
+		if s.model(m.Name) == nil {
+			continue
+		}
+
+		tags.Models = append(tags.Models, ollama.TagModel{
+			Name:       m.Name,
+			Model:      m.Name,
+			ModifiedAt: m.ModifiedAt,
+			Size:       int(m.Size),
+			Digest:     m.Digest,
+			Details:    tagDetails(m),
+		})
+	}
+
+	writeJSON(w, tags)
+}
+
+func (s *Server) show(w http.ResponseWriter, name string) {
+	m := s.model(name)
+	if m == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("model '%s' not found", name))
+		return
+	}
+
+	if m.ShowFile != "" {
+		data, err := os.ReadFile(m.ShowFile)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, fmt.Sprintf("reading show file: %+v", err))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		_, _ = w.Write(data)
+		return
+	}
+
+	if m.Show != nil {
+		writeJSON(w, m.Show)
+		return
+	}
+
+	family := m.Family
+	if family == "" {
+		family = "llama"
+	}
+
+	info := map[string]any{
+		"general.architecture":    family,
+		"general.parameter_count": m.ParameterCount,
+	}
+	for key, value := range map[string]int{
+		"context_length":          m.ContextLength,
+		"embedding_length":        m.EmbeddingLength,
+		"block_count":             m.BlockCount,
+		"attention.head_count":    m.HeadCount,
+		"attention.head_count_kv": m.HeadCountKV,
+	} {
+		if value > 0 {
+			info[family+"."+key] = value
+		}
+	}
+
+	show := map[string]any{
+		"details":      tagDetails(m),
+		"model_info":   info,
+		"capabilities": m.Capabilities,
+		"modified_at":  m.ModifiedAt,
+	}
+	if m.NumCtx > 0 {
+		show["modelfile"] = fmt.Sprintf("FROM %s\nPARAMETER num_ctx %d\n", m.Name, m.NumCtx)
+		show["parameters"] = fmt.Sprintf("num_ctx %d", m.NumCtx)
+	}
+
+	writeJSON(w, show)
+}
+
+func (s *Server) ps(w http.ResponseWriter) {
+	s.mu.Lock()
+	loaded := map[string]time.Time{}
+	for name, until := range s.loaded {
+		loaded[name] = until
+	}
+	contexts := map[string]int{}
+	for name, num_ctx := range s.contexts {
+		contexts[name] = num_ctx
+	}
+	s.mu.Unlock()
+
+	list := &ollama.ProcessList{Models: []ollama.ProcessModel{}}
+	for _, m := range s.list() {
+		until, ok := loaded[m.Name]
+		if !ok || s.model(m.Name) == nil {
+			continue
+		}
+
+		size_vram := m.SizeVRAM
+		if size_vram == 0 {
+			size_vram = m.Size
+		}
+
+		num_ctx, ok := contexts[m.Name]
+		if !ok {
+			num_ctx = m.NumCtx
+		}
+		if num_ctx <= 0 {
+			num_ctx = min(max(m.ContextLength, 0), defaultNumCtx)
+		}
+
+		list.Models = append(list.Models, ollama.ProcessModel{
+			Name:          m.Name,
+			Model:         m.Name,
+			Size:          max(m.Size, size_vram),
+			Digest:        m.Digest,
+			Details:       tagDetails(m),
+			ExpiresAt:     until,
+			SizeVRAM:      size_vram,
+			ContextLength: num_ctx,
+		})
+	}
+
+	writeJSON(w, list)
+}
+
+// generate loads or unloads the model when there's no prompt, otherwise it
+// streams the reply
+func (s *Server) generate(w http.ResponseWriter, r *http.Request, name string, body map[string]any, fault *Fault) {
+	m := s.model(name)
+	if m == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("model '%s' not found", name))
+		return
+	}
+
+	prompt, _ := body["prompt"].(string)
+	if prompt == "" {
+		reason := "load"
+		if keepAlive(body["keep_alive"]) == 0 {
+			s.mu.Lock()
+			delete(s.loaded, name)
+			delete(s.contexts, name)
+			s.mu.Unlock()
+			reason = "unload"
+		} else {
+			s.markLoaded(name, body)
+		}
+
+		writeJSON(w, &ollama.GenerateResponse{Model: name, CreatedAt: time.Now(), Done: true, DoneReason: reason})
+		return
+	}
+
+	s.markLoaded(name, body)
+
+	chunks := []any{}
+	words := strings.Fields(response(m))
+	for i, word := range words {
+		if i > 0 {
+			word = " " + word
+		}
+		chunks = append(chunks, &ollama.GenerateResponse{Model: name, CreatedAt: time.Now(), Response: word})
+	}
+	chunks = append(chunks, &ollama.GenerateResponse{
+		Model:      name,
+		CreatedAt:  time.Now(),
+		Done:       true,
+		DoneReason: "stop",
+		Metrics:    metrics(prompt, len(words)),
+	})
+
+	s.stream(w, r, body, chunks, fault)
+}
+
+func (s *Server) chat(w http.ResponseWriter, r *http.Request, name string, body map[string]any, fault *Fault) {
+	m := s.model(name)
+	if m == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("model '%s' not found", name))
+		return
+	}
+
+	s.markLoaded(name, body)
+
+	prompt := ""
+	if messages, ok := body["messages"].([]any); ok {
+		for _, message := range messages {
+			if message, ok := message.(map[string]any); ok {
+				content, _ := message["content"].(string)
+				prompt += content + " "
+			}
+		}
+	}
+
+	chunks := []any{}
+	words := strings.Fields(response(m))
+	for i, word := range words {
+		if i > 0 {
+			word = " " + word
+		}
+		chunks = append(chunks, &ollama.ChatResponse{Model: name, CreatedAt: time.Now(), Message: ollama.Message{Role: "assistant", Content: word}})
+	}
+	chunks = append(chunks, &ollama.ChatResponse{
+		Model:      name,
+		CreatedAt:  time.Now(),
+		Message:    ollama.Message{Role: "assistant"},
+		Done:       true,
+		DoneReason: "stop",
+		Metrics:    metrics(prompt, len(words)),
+	})
+
+	s.stream(w, r, body, chunks, fault)
+}
+
+// embed returns a vector for every input, the same input always gets the
+// same one
+func (s *Server) embed(w http.ResponseWriter, name string, body map[string]any) {
+	m := s.model(name)
+	if m == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("model \"%s\" not found, try pulling it first", name))
+		return
+	}
+
+	if len(m.Capabilities) > 0 && !contains(m.Capabilities, "embedding") {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("\"%s\" does not support embeddings", name))
+		return
+	}
+
+	inputs := []string{}
+	switch input := body["input"].(type) {
+	case string:
+		inputs = append(inputs, input)
+	case []any:
+		for _, i := range input {
+			text, _ := i.(string)
+			inputs = append(inputs, text)
+		}
+	}
+
+	dimensions := m.EmbeddingLength
+	if dimensions <= 0 {
+		dimensions = defaultDimensions
+	}
+
+	res := &ollama.EmbedResponse{Model: name, Embeddings: [][]float32{}}
+	for _, input := range inputs {
+		res.Embeddings = append(res.Embeddings, vector(input, dimensions))
+		res.PromptEvalCount += len(strings.Fields(input))
+	}
+
+	writeJSON(w, res)
+}
+
+// pull streams the progress of a download in a few steps, or only the last
+// one with `"stream": false`. Remote and deleted models of the fixture come
+// back
+func (s *Server) pull(w http.ResponseWriter, r *http.Request, name string, body map[string]any, fault *Fault) {
+	s.mu.Lock()
+	m, ok := s.models[name]
+	s.mu.Unlock()
+
+	if !ok {
+		s.stream(w, r, body, []any{map[string]string{"error": "pull model manifest: file does not exist"}}, nil)
+		return
+	}
+
+	chunks := []any{&ollama.ProgressResponse{Status: "pulling manifest"}}
+	for step := int64(1); step <= 4; step++ {
+		chunks = append(chunks, &ollama.ProgressResponse{
+			Status:    "pulling " + shortDigest(m.Digest),
+			Digest:    "sha256:" + m.Digest,
+			Total:     m.Size,
+			Completed: m.Size * step / 4,
+		})
+	}
+	chunks = append(chunks,
+		&ollama.ProgressResponse{Status: "verifying sha256 digest"},
+		&ollama.ProgressResponse{Status: "writing manifest"},
+		&ollama.ProgressResponse{Status: "success"},
+	)
+
+	if s.stream(w, r, body, chunks, fault) {
+		s.mu.Lock()
+		delete(s.removed, name)
+		s.mu.Unlock()
+	}
+}
+
+func (s *Server) delete(w http.ResponseWriter, name string) {
+	if s.model(name) == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("model '%s' not found", name))
+		return
+	}
+
+	s.mu.Lock()
+	s.removed[name] = true
+	delete(s.loaded, name)
+	delete(s.contexts, name)
+	s.mu.Unlock()
+}
+
+// copy adds the source model with the destination name, replacing the
+// model that had it
+func (s *Server) copy(w http.ResponseWriter, body map[string]any) {
+	source, _ := body["source"].(string)
+	destination, _ := body["destination"].(string)
+	if source == "" || destination == "" {
+		writeError(w, http.StatusBadRequest, "source and destination are required")
+		return
+	}
+
+	m := s.model(withTag(source))
+	if m == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("model '%s' not found", withTag(source)))
+		return
+	}
+
+	c := *m
+	c.Name, c.Loaded, c.Remote = withTag(destination), false, false
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if i := slices.IndexFunc(s.fixture.Models, func(m *Model) bool { return m.Name == c.Name }); i >= 0 {
+		s.fixture.Models[i] = &c
+	} else {
+		s.fixture.Models = append(s.fixture.Models, &c)
+	}
+	s.models[c.Name] = &c
+	delete(s.removed, c.Name)
+}
+
+// stream writes the chunks as NDJSON, or only the last one when the
+// request has `"stream": false`. A stream fault ends it after the first
+// chunk. It tells if the whole stream was sent
+func (s *Server) stream(w http.ResponseWriter, r *http.Request, body map[string]any, chunks []any, fault *Fault) bool {
+	if streaming, ok := body["stream"].(bool); ok && !streaming {
+		writeJSON(w, chunks[len(chunks)-1])
+		return true
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	flusher, _ := w.(http.Flusher)
+	enc := json.NewEncoder(w)
+
+	for i, chunk := range chunks {
+		if i > 0 && !sleep(r.Context(), s.fixture.ChunkLatency) {
+			return false
+		}
+
+		if fault != nil && i == 1 {
+			_ = enc.Encode(map[string]string{"error": fault.Message})
+			return false
+		}
+
+		_ = enc.Encode(chunk)
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	return true
+}
+
+// markLoaded keeps the model loaded for the request keep_alive, with the
+// num_ctx of its options, a request without it loads the default context
+func (s *Server) markLoaded(name string, body map[string]any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.loaded[name] = time.Now().Add(keepAlive(body["keep_alive"]))
+
+	options, _ := body["options"].(map[string]any)
+	if num_ctx, ok := options["num_ctx"].(float64); ok && num_ctx > 0 {
+		s.contexts[name] = int(num_ctx)
+	} else {
+		delete(s.contexts, name)
+	}
+}
+
+// keepAlive reads a keep_alive in seconds or as a duration, 5m by default
+func keepAlive(value any) time.Duration {
+	switch v := value.(type) {
+	case float64:
+		if v < 0 {
+			return 100 * 365 * 24 * time.Hour
+		}
+		return time.Duration(v * float64(time.Second))
+	case string:
+		if d, err := time.ParseDuration(v); err == nil {
+			if d < 0 {
+				return 100 * 365 * 24 * time.Hour
+			}
+			return d
+		}
+	}
+
+	return 5 * time.Minute
+}
+
+func tagDetails(m *Model) ollama.TagModelDetails {
+	details := ollama.TagModelDetails{
+		Format:            "gguf",
+		Family:            m.Family,
+		ParameterSize:     m.ParameterSize,
+		QuantizationLevel: m.QuantizationLevel,
+	}
+	if m.Family != "" {
+		details.Families = []string{m.Family}
+	}
+
+	return details
+}
+
+func response(m *Model) string {
+	if m.Response == "" {
+		return defaultResponse
+	}
+	return m.Response
+}
+
+// metrics are made up from the words, at 50 tokens per second
+func metrics(prompt string, eval_count int) ollama.Metrics {
+	eval_duration := time.Duration(eval_count) * 20 * time.Millisecond
+	return ollama.Metrics{
+		TotalDuration:      eval_duration + 10*time.Millisecond,
+		PromptEvalCount:    len(strings.Fields(prompt)),
+		PromptEvalDuration: 10 * time.Millisecond,
+		EvalCount:          eval_count,
+		EvalDuration:       eval_duration,
+	}
+}
+
+// vector returns a unit vector seeded by the input
+func vector(input string, dimensions int) []float32 {
+	var (
+		v    = make([]float32, dimensions)
+		norm float64
+	)
+
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(input))
+	seed := h.Sum64()
+
+	for i := range v {
+		seed = seed*6364136223846793005 + 1442695040888963407
+		v[i] = float32(int64(seed>>11))/float32(1<<52) - 1
+		norm += float64(v[i]) * float64(v[i])
+	}
+
+	if norm = math.Sqrt(norm); norm > 0 {
+		for i := range v {
+			v[i] = float32(float64(v[i]) / norm)
+		}
+	}
+
+	return v
+}
+
+func shortDigest(digest string) string {
+	if len(digest) > 12 {
+		return digest[:12]
+	}
+	return digest
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// sleep waits d, it tells false if the request was canceled first
+func sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
@@ -0,0 +1,242 @@
+package ollamatest
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/padiazg/ollama-tools/models/ollama"
+	"github.com/stretchr/testify/assert"
+)
+
+func newClient(s *Server) *ollama.Client {
+	return ollama.NewClient("http://ollama.test", ollama.WithTransport(s.Transport()))
+}
+
+func TestServer_models(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		s      = New(nil)
+		client = newClient(s)
+	)
+	defer client.Close()
+
+	tags, err := client.Tags(ctx)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, tags.Models, 3)
+	assert.Equal(t, "phi4:latest", tags.Models[0].Name)
+	assert.Equal(t, "Q4_K_M", tags.Models[0].Details.QuantizationLevel)
+
+	model, err := client.Show(ctx, "phi4")
+	if assert.NoError(t, err) {
+		assert.Equal(t, int64(14659507200), model.ModelInfo.ParameterCount)
+		assert.Equal(t, 16384, model.ModelInfo.ContextLength)
+		assert.Equal(t, 40, model.ModelInfo.BlockCount)
+	}
+
+	_, err = client.Show(ctx, "missing")
+	assert.ErrorContains(t, err, "model 'missing:latest' not found")
+
+	ps, err := client.Ps(ctx)
+	if assert.NoError(t, err) && assert.Len(t, ps.Models, 1) {
+		assert.Equal(t, "llama3.1:latest", ps.Models[0].Name)
+	}
+
+	assert.NoError(t, client.Delete(ctx, "phi4:latest"))
+	assert.Error(t, client.Delete(ctx, "phi4:latest"))
+
+	tags, _ = client.Tags(ctx)
+	assert.Len(t, tags.Models, 2)
+
+	statuses := []string{}
+	err = client.Pull(ctx, &ollama.PullRequest{Model: "phi4"}, func(p *ollama.ProgressResponse) error {
+		statuses = append(statuses, p.Status)
+		return nil
+	})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "pulling manifest", statuses[0])
+		assert.Equal(t, "success", statuses[len(statuses)-1])
+	}
+
+	tags, _ = client.Tags(ctx)
+	assert.Len(t, tags.Models, 3)
+
+	err = client.Pull(ctx, &ollama.PullRequest{Model: "nope"}, func(p *ollama.ProgressResponse) error { return nil })
+	assert.ErrorContains(t, err, "file does not exist")
+
+	// without streaming only the last update comes back
+	statuses = []string{}
+	no_stream := false
+	err = client.Pull(ctx, &ollama.PullRequest{Model: "phi4", Stream: &no_stream}, func(p *ollama.ProgressResponse) error {
+		statuses = append(statuses, p.Status)
+		return nil
+	})
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"success"}, statuses)
+	}
+
+	assert.NoError(t, client.Copy(ctx, "phi4", "team/chat:current"))
+	assert.ErrorContains(t, client.Copy(ctx, "nope", "team/chat:current"), "not found")
+
+	tags, _ = client.Tags(ctx)
+	if assert.Len(t, tags.Models, 4) {
+		assert.Equal(t, "team/chat:current", tags.Models[3].Name)
+		assert.Equal(t, tags.Models[0].Digest, tags.Models[3].Digest)
+	}
+
+	assert.Equal(t, 4, s.Calls(ollama.ApiPathTags))
+}
+
+func TestServer_generate(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		s      = New(nil)
+		client = newClient(s)
+	)
+	defer client.Close()
+
+	res, err := client.Generate(ctx, &ollama.GenerateRequest{Model: "phi4"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "load", res.DoneReason)
+	}
+
+	ps, _ := client.Ps(ctx)
+	if assert.Len(t, ps.Models, 2) {
+		assert.Equal(t, 4096, ps.Models[0].ContextLength, "the default context")
+	}
+
+	// ps reports the num_ctx the model was loaded with
+	_, err = client.Generate(ctx, &ollama.GenerateRequest{Model: "phi4", Options: map[string]any{"num_ctx": 8192}})
+	if assert.NoError(t, err) {
+		ps, _ = client.Ps(ctx)
+		assert.Equal(t, 8192, ps.Models[0].ContextLength)
+	}
+
+	res, err = client.Generate(ctx, &ollama.GenerateRequest{Model: "phi4", KeepAlive: 0})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "unload", res.DoneReason)
+	}
+
+	res, err = client.Generate(ctx, &ollama.GenerateRequest{Model: "phi4", Prompt: "hi there"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "stop", res.DoneReason)
+		assert.Equal(t, 2, res.PromptEvalCount)
+		assert.Equal(t, 10, res.EvalCount)
+	}
+
+	reply := ""
+	err = client.Chat(ctx, &ollama.ChatRequest{Model: "llama3.1", Messages: []ollama.Message{{Role: "user", Content: "hi"}}}, func(r *ollama.ChatResponse) error {
+		reply += r.Message.Content
+		return nil
+	})
+	if assert.NoError(t, err) {
+		assert.Equal(t, defaultResponse, reply)
+	}
+}
+
+func TestServer_embed(t *testing.T) {
+	var (
+		ctx    = context.Background()
+		client = newClient(New(nil))
+	)
+	defer client.Close()
+
+	res, err := client.Embed(ctx, &ollama.EmbedRequest{Model: "nomic-embed-text", Input: []string{"a", "b", "a"}})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, res.Embeddings, 3)
+	assert.Len(t, res.Embeddings[0], 768)
+	assert.Equal(t, res.Embeddings[0], res.Embeddings[2])
+	assert.NotEqual(t, res.Embeddings[0], res.Embeddings[1])
+
+	_, err = client.Embed(ctx, &ollama.EmbedRequest{Model: "phi4", Input: []string{"a"}})
+	assert.ErrorContains(t, err, "does not support embeddings")
+}
+
+func TestServer_faults(t *testing.T) {
+	fixture, err := ParseFixture([]byte(`
+chunk_latency: 1ms
+models:
+  - name: slow
+    latency: 1s
+  - name: tiny
+    response: one two three
+errors:
+  - path: /api/tags
+    status: 503
+    message: overloaded
+    times: 1
+  - path: /api/chat
+    model: tiny
+    message: connection lost
+    stream: true
+`), "")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var (
+		ctx    = context.Background()
+		s      = New(fixture)
+		client = newClient(s)
+	)
+	defer client.Close()
+
+	_, err = client.Tags(ctx)
+	if se, ok := err.(*ollama.StatusError); assert.True(t, ok, "got %+v", err) {
+		assert.Equal(t, http.StatusServiceUnavailable, se.StatusCode)
+		assert.Equal(t, "overloaded", se.Message)
+	}
+
+	tags, err := client.Tags(ctx)
+	if assert.NoError(t, err, "the fault only happens once") {
+		assert.Len(t, tags.Models, 2)
+	}
+
+	chunks := 0
+	err = client.Chat(ctx, &ollama.ChatRequest{Model: "tiny"}, func(r *ollama.ChatResponse) error {
+		chunks++
+		return nil
+	})
+	assert.ErrorContains(t, err, "connection lost")
+	assert.Equal(t, 1, chunks)
+
+	ctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+
+	started := time.Now()
+	_, err = client.Show(ctx, "slow")
+	assert.Error(t, err)
+	assert.Less(t, time.Since(started), time.Second)
+}
+
+func TestParseFixture(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{name: "default", data: "models:\n  - name: phi4\n"},
+		{name: "no-name", data: "models:\n  - size: 1\n", wantErr: "model without a name"},
+		{name: "no-path", data: "errors:\n  - status: 500\n", wantErr: "error without a path"},
+		{name: "invalid", data: "models: [", wantErr: "decoding fixture"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := ParseFixture([]byte(tt.data), "")
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.True(t, strings.HasSuffix(f.Models[0].Name, ":latest"))
+			}
+		})
+	}
+}
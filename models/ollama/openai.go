@@ -0,0 +1,284 @@
+package ollama
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+const (
+	ApiPathOpenAIModels = "/v1/models"
+	// llama.cpp only
+	ApiPathProps = "/props"
+	ApiPathSlots = "/slots"
+)
+
+// OpenAIModel is a model as `/v1/models` lists it. llama.cpp adds Meta
+// and vLLM adds MaxModelLen, other servers only send the id
+type OpenAIModel struct {
+	ID          string           `json:"id"`
+	OwnedBy     string           `json:"owned_by"`
+	Created     int64            `json:"created"`
+	MaxModelLen int              `json:"max_model_len"`
+	Meta        *OpenAIModelMeta `json:"meta"`
+}
+
+// OpenAIModelMeta is the GGUF metadata llama.cpp reports
+type OpenAIModelMeta struct {
+	NVocab    int   `json:"n_vocab"`
+	NCtxTrain int   `json:"n_ctx_train"`
+	NEmbd     int   `json:"n_embd"`
+	NParams   int64 `json:"n_params"`
+	Size      int64 `json:"size"`
+}
+
+type OpenAIModels struct {
+	Data []OpenAIModel `json:"data"`
+}
+
+// Props is the llama.cpp server setup, NCtx is the context of each slot
+type Props struct {
+	DefaultGenerationSettings struct {
+		NCtx int `json:"n_ctx"`
+	} `json:"default_generation_settings"`
+	TotalSlots int    `json:"total_slots"`
+	ModelPath  string `json:"model_path"`
+}
+
+// Slot is one of the parallel sequences of a llama.cpp server
+type Slot struct {
+	ID   int `json:"id"`
+	NCtx int `json:"n_ctx"`
+}
+
+// OpenAIBackend reads models from an OpenAI compatible server, like
+// llama.cpp's llama-server, LM Studio or vLLM. Those don't report as much
+// as `/api/show`, the missing values are left for the caller to infer
+type OpenAIBackend struct {
+	client *Client
+
+	mu    sync.Mutex
+	state *openAIState
+	// llama.cpp endpoints that returned a 404, they aren't asked again
+	missing map[string]bool
+}
+
+// openAIState is what the server reported, Tags asks again for the models
+// and Show fetches `/props` and `/slots` once after that
+type openAIState struct {
+	models  *OpenAIModels
+	props   *Props
+	slots   []Slot
+	fetched bool
+}
+
+// NewOpenAIBackend takes the same options as NewClient
+func NewOpenAIBackend(base_url string, opts ...ClientOption) *OpenAIBackend {
+	return &OpenAIBackend{client: NewClient(base_url, opts...), missing: map[string]bool{}}
+}
+
+func (b *OpenAIBackend) BaseUrl() string {
+	return b.client.BaseUrl()
+}
+
+func (b *OpenAIBackend) Close() error {
+	return b.client.Close()
+}
+
+// Models lists the models the server serves
+func (b *OpenAIBackend) Models(ctx context.Context) (*OpenAIModels, error) {
+	models := &OpenAIModels{}
+	if err := b.client.do(ctx, http.MethodGet, ApiPathOpenAIModels, nil, models); err != nil {
+		return nil, err
+	}
+
+	return models, nil
+}
+
+// Props returns the llama.cpp server setup
+func (b *OpenAIBackend) Props(ctx context.Context) (*Props, error) {
+	props := &Props{}
+	if err := b.client.do(ctx, http.MethodGet, ApiPathProps, nil, props); err != nil {
+		return nil, err
+	}
+
+	return props, nil
+}
+
+// Slots returns the llama.cpp slots, servers started with --no-slots
+// don't list them
+func (b *OpenAIBackend) Slots(ctx context.Context) ([]Slot, error) {
+	slots := []Slot{}
+	if err := b.client.do(ctx, http.MethodGet, ApiPathSlots, nil, &slots); err != nil {
+		return nil, err
+	}
+
+	return slots, nil
+}
+
+// Tags lists the models as `/api/tags` would
+func (b *OpenAIBackend) Tags(ctx context.Context) (*Tags, error) {
+	models, err := b.Models(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	b.state = &openAIState{models: models}
+	b.mu.Unlock()
+
+	tags := &Tags{Models: make([]TagModel, 0, len(models.Data))}
+	for _, m := range models.Data {
+		details := openAIDetails(&m, "")
+		tag := TagModel{
+			Name:  m.ID,
+			Model: m.ID,
+			Details: TagModelDetails{
+				Format:            details.Format,
+				ParameterSize:     details.ParameterSize,
+				QuantizationLevel: details.QuantizationLevel,
+			},
+		}
+		if m.Meta != nil {
+			tag.Size = int(m.Meta.Size)
+		}
+
+		tags.Models = append(tags.Models, tag)
+	}
+
+	return tags, nil
+}
+
+// Show describes the model as `/api/show` would, with what `/v1/models`
+// reports, plus `/props` and `/slots` on llama.cpp
+func (b *OpenAIBackend) Show(ctx context.Context, model_name string) (*Model, error) {
+	state, err := b.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var found *OpenAIModel
+	for i := range state.models.Data {
+		if state.models.Data[i].ID == model_name {
+			found = &state.models.Data[i]
+			break
+		}
+	}
+
+	if found == nil {
+		return nil, &StatusError{StatusCode: http.StatusNotFound, Message: fmt.Sprintf("model '%s' not found", model_name)}
+	}
+
+	// the model path tells the quantization when the id is an alias, other
+	// servers don't have these endpoints
+	model_path := ""
+	props, slots := state.props, state.slots
+	if props != nil {
+		model_path = props.ModelPath
+	}
+
+	model := &Model{Details: openAIDetails(found, model_path)}
+	model.ModelInfo.ContextLength = found.MaxModelLen
+	if found.Meta != nil {
+		model.ModelInfo.ParameterCount = found.Meta.NParams
+		model.ModelInfo.ContextLength = found.Meta.NCtxTrain
+		model.ModelInfo.EmbeddingLength = found.Meta.NEmbd
+	}
+
+	if props != nil {
+		model.NumCtx = props.DefaultGenerationSettings.NCtx * max(props.TotalSlots, 1)
+	}
+
+	// the KV cache holds the context of every slot
+	if len(slots) > 0 {
+		model.NumCtx = 0
+		for _, slot := range slots {
+			model.NumCtx += slot.NCtx
+		}
+	}
+
+	return model, nil
+}
+
+// load returns the state from the last Tags call, or asks for it, along
+// with `/props` and `/slots` the first time
+func (b *OpenAIBackend) load(ctx context.Context) (*openAIState, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == nil {
+		models, err := b.Models(ctx)
+		if err != nil {
+			return nil, err
+		}
+		b.state = &openAIState{models: models}
+	}
+
+	if b.state.fetched {
+		return b.state, nil
+	}
+
+	if !b.missing[ApiPathProps] {
+		props, err := b.Props(ctx)
+		b.missing[ApiPathProps] = isNotFound(err)
+		b.state.props = props
+	}
+
+	if !b.missing[ApiPathSlots] {
+		slots, err := b.Slots(ctx)
+		b.missing[ApiPathSlots] = isNotFound(err)
+		b.state.slots = slots
+	}
+	b.state.fetched = true
+
+	return b.state, nil
+}
+
+func isNotFound(err error) bool {
+	var se *StatusError
+	return errors.As(err, &se) && se.StatusCode == http.StatusNotFound
+}
+
+var (
+	quantizationPattern  = regexp.MustCompile(`(?i)(?:^|[-_.@ ])(I?Q\d(?:_K)?(?:_[0-9A-Z]{1,2})?|BF16|F16|F32)(?:$|[-_. ])`)
+	parameterSizePattern = regexp.MustCompile(`(?i)(?:^|[-_.:/ ])((?:\d+x)?\d+(?:\.\d+)?[BM])(?:$|[-_.: ])`)
+)
+
+// openAIDetails fills the details from the model metadata, or guesses them
+// from its id or file name, like `qwen2.5-7b-instruct-q4_k_m.gguf`
+func openAIDetails(m *OpenAIModel, model_path string) ModelDetails {
+	var (
+		details = ModelDetails{}
+		names   = []string{m.ID, path.Base(model_path)}
+	)
+
+	if m.Meta != nil {
+		details.Format = "gguf"
+		if m.Meta.NParams > 0 {
+			details.ParameterSize = fmt.Sprintf("%.1fB", float64(m.Meta.NParams)/1_000_000_000)
+		}
+	}
+
+	for _, name := range names {
+		name = strings.TrimSuffix(name, ".gguf")
+
+		if details.QuantizationLevel == "" {
+			if found := quantizationPattern.FindStringSubmatch(name); found != nil {
+				details.QuantizationLevel = strings.ToUpper(found[1])
+			}
+		}
+
+		if details.ParameterSize == "" {
+			if found := parameterSizePattern.FindStringSubmatch(name); found != nil {
+				details.ParameterSize = strings.ToUpper(found[1])
+			}
+		}
+	}
+
+	return details
+}
@@ -0,0 +1,163 @@
+package ollama
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// pathCounter counts the requests to every path
+type pathCounter struct {
+	mu    sync.Mutex
+	paths map[string]int
+}
+
+func (c *pathCounter) count(path string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.paths[path]
+}
+
+func openAIServer(t *testing.T, llamacpp bool, calls *pathCounter) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+
+		if calls != nil {
+			calls.mu.Lock()
+			calls.paths[r.URL.Path]++
+			calls.mu.Unlock()
+		}
+
+		switch {
+		case r.URL.Path == ApiPathOpenAIModels && llamacpp:
+			_, _ = w.Write([]byte(`{"object":"list","data":[{"id":"coder","object":"model","owned_by":"llamacpp",` +
+				`"meta":{"n_vocab":152064,"n_ctx_train":32768,"n_embd":3584,"n_params":7615616512,"size":4677120000}}]}`))
+		case r.URL.Path == ApiPathOpenAIModels:
+			_, _ = w.Write([]byte(`{"object":"list","data":[{"id":"Qwen/Qwen2.5-7B-Instruct","object":"model","owned_by":"vllm","max_model_len":32768}]}`))
+		case r.URL.Path == ApiPathProps && llamacpp:
+			_, _ = w.Write([]byte(`{"default_generation_settings":{"n_ctx":4096},"total_slots":2,"model_path":"/models/qwen2.5-coder-7b-instruct-q4_k_m.gguf"}`))
+		case r.URL.Path == ApiPathSlots && llamacpp:
+			_, _ = w.Write([]byte(`[{"id":0,"n_ctx":4096},{"id":1,"n_ctx":4096}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestOpenAIBackend_Tags(t *testing.T) {
+	ts := openAIServer(t, true, nil)
+	defer ts.Close()
+
+	b := NewOpenAIBackend(ts.URL)
+	defer b.Close()
+
+	got, err := b.Tags(context.Background())
+	if assert.NoError(t, err) && assert.Len(t, got.Models, 1) {
+		assert.Equal(t, "coder", got.Models[0].Name)
+		assert.Equal(t, 4677120000, got.Models[0].Size)
+		assert.Equal(t, "7.6B", got.Models[0].Details.ParameterSize)
+		assert.Equal(t, "gguf", got.Models[0].Details.Format)
+	}
+}
+
+func TestOpenAIBackend_Show(t *testing.T) {
+	t.Run("llama.cpp", func(t *testing.T) {
+		ts := openAIServer(t, true, nil)
+		defer ts.Close()
+
+		b := NewOpenAIBackend(ts.URL)
+		defer b.Close()
+
+		got, err := b.Show(context.Background(), "coder")
+		if assert.NoError(t, err) {
+			assert.Equal(t, int64(7615616512), got.ModelInfo.ParameterCount)
+			assert.Equal(t, 32768, got.ModelInfo.ContextLength)
+			assert.Equal(t, 3584, got.ModelInfo.EmbeddingLength)
+			// the quantization comes from the model path
+			assert.Equal(t, "Q4_K_M", got.Details.QuantizationLevel)
+			assert.Equal(t, 8192, got.NumCtx)
+		}
+	})
+
+	t.Run("vllm", func(t *testing.T) {
+		ts := openAIServer(t, false, nil)
+		defer ts.Close()
+
+		b := NewOpenAIBackend(ts.URL)
+		defer b.Close()
+
+		got, err := b.Show(context.Background(), "Qwen/Qwen2.5-7B-Instruct")
+		if assert.NoError(t, err) {
+			assert.Equal(t, int64(0), got.ModelInfo.ParameterCount)
+			assert.Equal(t, "7B", got.Details.ParameterSize)
+			assert.Equal(t, 32768, got.ModelInfo.ContextLength)
+			assert.Equal(t, "", got.Details.QuantizationLevel)
+			assert.Equal(t, 0, got.NumCtx)
+		}
+
+		_, err = b.Show(context.Background(), "missing")
+		assert.ErrorContains(t, err, "model 'missing' not found")
+	})
+
+	t.Run("requests", func(t *testing.T) {
+		for _, llamacpp := range []bool{true, false} {
+			var (
+				calls = &pathCounter{paths: map[string]int{}}
+				ts    = openAIServer(t, llamacpp, calls)
+				b     = NewOpenAIBackend(ts.URL)
+				ctx   = context.Background()
+			)
+
+			// two listings, every model is shown once after its Tags call
+			for range 2 {
+				tags, err := b.Tags(ctx)
+				if !assert.NoError(t, err) {
+					break
+				}
+				for range 3 {
+					_, err = b.Show(ctx, tags.Models[0].Name)
+					assert.NoError(t, err)
+				}
+			}
+			b.Close()
+			ts.Close()
+
+			assert.Equal(t, 2, calls.count(ApiPathOpenAIModels), "llama.cpp %t", llamacpp)
+			if llamacpp {
+				assert.Equal(t, 2, calls.count(ApiPathProps))
+				assert.Equal(t, 2, calls.count(ApiPathSlots))
+			} else {
+				// a 404 is remembered
+				assert.Equal(t, 1, calls.count(ApiPathProps))
+				assert.Equal(t, 1, calls.count(ApiPathSlots))
+			}
+		}
+	})
+}
+
+func Test_openAIDetails(t *testing.T) {
+	tests := []struct {
+		id               string
+		wantSize         string
+		wantQuantization string
+	}{
+		{id: "qwen2.5-coder-7b-instruct-q4_k_m.gguf", wantSize: "7B", wantQuantization: "Q4_K_M"},
+		{id: "Meta-Llama-3.1-8B-Instruct-Q8_0.gguf", wantSize: "8B", wantQuantization: "Q8_0"},
+		{id: "mixtral-8x7b-instruct@iq4_xs", wantSize: "8X7B", wantQuantization: "IQ4_XS"},
+		{id: "gemma-2-2.6b-it-bf16", wantSize: "2.6B", wantQuantization: "BF16"},
+		{id: "text-embedding-nomic-embed-text-v1.5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			got := openAIDetails(&OpenAIModel{ID: tt.id}, "")
+			assert.Equal(t, tt.wantSize, got.ParameterSize)
+			assert.Equal(t, tt.wantQuantization, got.QuantizationLevel)
+		})
+	}
+}
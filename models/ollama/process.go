@@ -0,0 +1,19 @@
+package ollama
+
+import "time"
+
+// ProcessModel is a model loaded in memory, as reported by `/api/ps`
+type ProcessModel struct {
+	Name          string          `json:"name"`
+	Model         string          `json:"model"`
+	Size          int64           `json:"size"`
+	Digest        string          `json:"digest"`
+	Details       TagModelDetails `json:"details"`
+	ExpiresAt     time.Time       `json:"expires_at"`
+	SizeVRAM      int64           `json:"size_vram"`
+	ContextLength int             `json:"context_length"`
+}
+
+type ProcessList struct {
+	Models []ProcessModel `json:"models"`
+}
@@ -0,0 +1,91 @@
+package ollama
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"time"
+)
+
+// RetryPolicy tells how idempotent calls are retried when the server is
+// busy or the request times out. The wait doubles on every attempt up to
+// MaxWait, with jitter, and no attempt starts after MaxElapsed
+type RetryPolicy struct {
+	Retries    int
+	Wait       time.Duration
+	MaxWait    time.Duration
+	MaxElapsed time.Duration
+}
+
+// WithRetry sets the retry policy, no retries are made without it
+func WithRetry(policy RetryPolicy) ClientOption {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// retryableStatus are the statuses of a busy or restarting server, anything
+// else won't change by asking again
+var retryableStatus = map[int]bool{
+	http.StatusRequestTimeout:     true,
+	http.StatusTooManyRequests:    true,
+	http.StatusBadGateway:         true,
+	http.StatusServiceUnavailable: true,
+	http.StatusGatewayTimeout:     true,
+}
+
+// idempotentPosts are the POST endpoints that only read, so they can be
+// sent again safely
+var idempotentPosts = map[string]bool{
+	ApiPathShow:  true,
+	ApiPathEmbed: true,
+}
+
+func idempotent(method string, path string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead:
+		return true
+	case http.MethodPost:
+		return idempotentPosts[path]
+	}
+	return false
+}
+
+// retryable tells if a failed attempt is worth repeating, transport errors
+// and timeouts are, and so are the statuses of a busy server. Certificate
+// errors aren't
+func retryable(err error) bool {
+	var se *StatusError
+	if errors.As(err, &se) {
+		return retryableStatus[se.StatusCode]
+	}
+
+	var te *TLSError
+	return !errors.As(err, &te)
+}
+
+// backoff returns the wait before the next attempt, attempt starts at 1.
+// The jitter keeps it between half and the whole exponential wait
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	wait := p.Wait
+	if wait <= 0 {
+		wait = 500 * time.Millisecond
+	}
+
+	for i := 1; i < attempt; i++ {
+		wait *= 2
+		if p.MaxWait > 0 && wait >= p.MaxWait {
+			wait = p.MaxWait
+			break
+		}
+	}
+
+	return wait/2 + rand.N(wait/2+1)
+}
+
+func (c *Client) logf(format string, args ...any) {
+	if c.logger != nil {
+		fmt.Fprintf(c.logger, format+"\n", args...)
+	}
+}
@@ -0,0 +1,145 @@
+package ollama
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_Retry(t *testing.T) {
+	policy := RetryPolicy{Retries: 3, Wait: time.Millisecond, MaxWait: 2 * time.Millisecond}
+
+	tests := []struct {
+		name         string
+		failures     int32
+		status       int
+		stall        bool
+		call         func(c *Client) error
+		wantAttempts int32
+		wantErr      string
+	}{
+		{
+			name:         "busy-then-ok",
+			failures:     2,
+			status:       http.StatusServiceUnavailable,
+			call:         func(c *Client) error { _, err := c.Show(context.Background(), "phi4"); return err },
+			wantAttempts: 3,
+		},
+		{
+			name:         "stalled-then-ok",
+			failures:     1,
+			stall:        true,
+			call:         func(c *Client) error { _, err := c.Tags(context.Background()); return err },
+			wantAttempts: 2,
+		},
+		{
+			name:         "always-busy",
+			failures:     100,
+			status:       http.StatusServiceUnavailable,
+			call:         func(c *Client) error { _, err := c.Tags(context.Background()); return err },
+			wantAttempts: 4,
+			wantErr:      "response status code: 503: busy, after 4 attempts",
+		},
+		{
+			name:         "not-found",
+			failures:     100,
+			status:       http.StatusNotFound,
+			call:         func(c *Client) error { _, err := c.Show(context.Background(), "phi4"); return err },
+			wantAttempts: 1,
+			wantErr:      "response status code: 404: busy",
+		},
+		{
+			name:         "not-idempotent",
+			failures:     100,
+			status:       http.StatusServiceUnavailable,
+			call:         func(c *Client) error { return c.Copy(context.Background(), "a", "b") },
+			wantAttempts: 1,
+			wantErr:      "response status code: 503",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				attempts atomic.Int32
+				log      = &bytes.Buffer{}
+				ts       = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if attempts.Add(1) <= tt.failures {
+						if tt.stall {
+							<-r.Context().Done()
+							return
+						}
+						w.WriteHeader(tt.status)
+						_, _ = w.Write([]byte(`{"error":"busy"}`))
+						return
+					}
+					_, _ = w.Write([]byte(`{"models":[],"details":{"family":"llama"}}`))
+				}))
+			)
+			defer ts.Close()
+
+			c := NewClient(ts.URL, WithRetry(policy), WithTimeout(50*time.Millisecond), WithLogger(log))
+			defer c.Close()
+
+			err := tt.call(c)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+
+				// the status is kept after the retries
+				var se *StatusError
+				if assert.ErrorAs(t, err, &se) {
+					assert.Equal(t, tt.status, se.StatusCode)
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+
+			assert.Equal(t, tt.wantAttempts, attempts.Load())
+			if tt.wantAttempts > 1 {
+				assert.Contains(t, log.String(), "failed")
+				assert.Contains(t, log.String(), "retrying in")
+			} else {
+				assert.Empty(t, log.String())
+			}
+		})
+	}
+}
+
+func TestClient_RetryMaxElapsed(t *testing.T) {
+	var (
+		attempts atomic.Int32
+		ts       = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts.Add(1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+	)
+	defer ts.Close()
+
+	c := NewClient(ts.URL, WithRetry(RetryPolicy{Retries: 100, Wait: 20 * time.Millisecond, MaxWait: 20 * time.Millisecond, MaxElapsed: 100 * time.Millisecond}))
+	defer c.Close()
+
+	start := time.Now()
+	_, err := c.Tags(context.Background())
+
+	assert.ErrorContains(t, err, "gave up after")
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Less(t, attempts.Load(), int32(100))
+}
+
+func TestRetryPolicy_backoff(t *testing.T) {
+	p := &RetryPolicy{Wait: 100 * time.Millisecond, MaxWait: time.Second}
+
+	for attempt, want := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 4: 800 * time.Millisecond, 10: time.Second} {
+		for range 20 {
+			got := p.backoff(attempt)
+			assert.GreaterOrEqual(t, got, want/2)
+			assert.LessOrEqual(t, got, want)
+		}
+	}
+}
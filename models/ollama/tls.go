@@ -0,0 +1,57 @@
+package ollama
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// clientCertAlerts are the alerts a server sends when it wants a client
+// certificate it trusts, crypto/tls doesn't export their type
+var clientCertAlerts = []string{"tls: certificate required", "tls: bad certificate"}
+
+// TLSError is a failed certificate verification, asking again won't help
+type TLSError struct {
+	Message string
+}
+
+func (e *TLSError) Error() string {
+	return e.Message
+}
+
+// tlsError explains why a TLS handshake failed and what setting to look
+// at, nil if err is not about certificates
+func tlsError(err error) *TLSError {
+	var (
+		unknown   x509.UnknownAuthorityError
+		hostname  x509.HostnameError
+		invalid   x509.CertificateInvalidError
+		verifying *tls.CertificateVerificationError
+	)
+
+	switch {
+	case errors.As(err, &unknown):
+		return &TLSError{Message: fmt.Sprintf("the server certificate is signed by an unknown authority, set tls.cafile to its CA bundle or tls.insecure to skip verification: %+v", err)}
+	case errors.As(err, &hostname):
+		return &TLSError{Message: fmt.Sprintf("the server certificate is not valid for %s, set tls.servername to a name it's valid for: %+v", hostname.Host, err)}
+	case errors.As(err, &invalid):
+		return &TLSError{Message: fmt.Sprintf("the server certificate is not valid: %+v", err)}
+	case containsAny(err.Error(), clientCertAlerts):
+		return &TLSError{Message: fmt.Sprintf("the server requires a client certificate it trusts, set tls.certfile and tls.keyfile: %+v", err)}
+	case errors.As(err, &verifying):
+		return &TLSError{Message: fmt.Sprintf("verifying the server certificate: %+v", err)}
+	}
+
+	return nil
+}
+
+func containsAny(s string, subs []string) bool {
+	for _, sub := range subs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
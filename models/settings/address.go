@@ -0,0 +1,94 @@
+package settings
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	DefaultOllamaPort = "11434"
+
+	SourceEnv        = "OT_OLLAMAURL"
+	SourceOllamaHost = "OLLAMA_HOST"
+	SourceConfig     = "config file"
+	SourceDefault    = "default"
+)
+
+// NormalizeHost turns an address in any of the forms the ollama cli takes
+// in OLLAMA_HOST into a url: `host`, `host:port`, `:port`, `0.0.0.0`,
+// `[::1]:11434` or a full url. The port defaults to 11434, or to the
+// scheme port when one is given, and addresses that only make sense to
+// listen on, like 0.0.0.0, are reached on 127.0.0.1
+func NormalizeHost(address string) (string, error) {
+	address = strings.TrimSpace(address)
+
+	scheme, hostport, ok := strings.Cut(address, "://")
+	default_port := DefaultOllamaPort
+	switch {
+	case !ok:
+		scheme, hostport = "http", address
+	case scheme == UnixSocketScheme:
+		return address, nil
+	case scheme == "http":
+		default_port = "80"
+	case scheme == "https":
+		default_port = "443"
+	default:
+		return "", fmt.Errorf("unsupported scheme %s in %s", scheme, address)
+	}
+
+	hostport, path, _ := strings.Cut(hostport, "/")
+
+	host, port, err := net.SplitHostPort(hostport)
+	if err != nil {
+		host, port = strings.Trim(hostport, "[]"), default_port
+	}
+
+	if n, err := strconv.Atoi(port); err != nil || n < 0 || n > 65535 {
+		return "", fmt.Errorf("invalid port %s in %s", port, address)
+	}
+
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = "127.0.0.1"
+	}
+
+	u := &url.URL{Scheme: scheme, Host: net.JoinHostPort(host, port)}
+	if path = strings.TrimSuffix(path, "/"); path != "" {
+		u.Path = "/" + path
+	}
+
+	return u.String(), nil
+}
+
+// ResolveOllamaUrl picks the server address and normalizes it. OT_OLLAMAURL
+// and the config file come first, then OLLAMA_HOST like the ollama cli,
+// then the default. in_config tells if the config file sets `ollamaurl`
+func (s *Settings) ResolveOllamaUrl(in_config bool, config_file string) error {
+	switch {
+	case os.Getenv(SourceEnv) != "":
+		s.source = SourceEnv
+	case in_config:
+		s.source = fmt.Sprintf("%s %s", SourceConfig, config_file)
+	case os.Getenv(SourceOllamaHost) != "":
+		s.OllamaUrl, s.source = os.Getenv(SourceOllamaHost), SourceOllamaHost
+	default:
+		s.source = SourceDefault
+	}
+
+	ollama_url, err := NormalizeHost(s.OllamaUrl)
+	if err != nil {
+		return fmt.Errorf("%s: %+v", s.source, err)
+	}
+	s.OllamaUrl = ollama_url
+
+	return nil
+}
+
+// OllamaUrlSource tells where the server address came from
+func (s *Settings) OllamaUrlSource() string {
+	return s.source
+}
@@ -0,0 +1,81 @@
+package settings
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeHost(t *testing.T) {
+	tests := []struct {
+		address string
+		want    string
+		wantErr string
+	}{
+		{address: "", want: "http://127.0.0.1:11434"},
+		{address: "0.0.0.0", want: "http://127.0.0.1:11434"},
+		{address: "0.0.0.0:8080", want: "http://127.0.0.1:8080"},
+		{address: ":9999", want: "http://127.0.0.1:9999"},
+		{address: "[::]:11434", want: "http://127.0.0.1:11434"},
+		{address: "ollama.internal", want: "http://ollama.internal:11434"},
+		{address: "192.168.1.100:11434", want: "http://192.168.1.100:11434"},
+		{address: "[::1]", want: "http://[::1]:11434"},
+		{address: "::1", want: "http://[::1]:11434"},
+		{address: " http://localhost:11434/ ", want: "http://localhost:11434"},
+		{address: "http://ollama.internal", want: "http://ollama.internal:80"},
+		{address: "https://ollama.internal", want: "https://ollama.internal:443"},
+		{address: "https://gateway.internal/ollama/", want: "https://gateway.internal:443/ollama"},
+		{address: "unix:///run/ollama.sock", want: "unix:///run/ollama.sock"},
+		{address: "ollama.internal:port", wantErr: "invalid port port"},
+		{address: "ftp://ollama.internal", wantErr: "unsupported scheme ftp"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			got, err := NormalizeHost(tt.address)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
+
+func TestSettings_ResolveOllamaUrl(t *testing.T) {
+	tests := []struct {
+		name        string
+		env         string
+		ollama_host string
+		in_config   bool
+		ollama_url  string
+		want        string
+		wantSource  string
+	}{
+		{name: "default", ollama_url: "http://localhost:11434", want: "http://localhost:11434", wantSource: SourceDefault},
+		{name: "ollama-host", ollama_host: "0.0.0.0:8080", ollama_url: "http://localhost:11434", want: "http://127.0.0.1:8080", wantSource: SourceOllamaHost},
+		{name: "config-first", ollama_host: ":8080", in_config: true, ollama_url: "gpu-box", want: "http://gpu-box:11434", wantSource: "config file /home/pato/.ollama-tools.yaml"},
+		{name: "env-first", env: "gpu-box:9000", ollama_host: ":8080", in_config: true, ollama_url: "gpu-box:9000", want: "http://gpu-box:9000", wantSource: SourceEnv},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(SourceEnv, tt.env)
+			t.Setenv(SourceOllamaHost, tt.ollama_host)
+
+			s := &Settings{OllamaUrl: tt.ollama_url}
+			if !assert.NoError(t, s.ResolveOllamaUrl(tt.in_config, "/home/pato/.ollama-tools.yaml")) {
+				return
+			}
+
+			assert.Equal(t, tt.want, s.OllamaUrl)
+			assert.Equal(t, tt.wantSource, s.OllamaUrlSource())
+		})
+	}
+
+	t.Setenv(SourceOllamaHost, "ollama:nope")
+	s := &Settings{}
+	assert.ErrorContains(t, s.ResolveOllamaUrl(false, ""), "OLLAMA_HOST: invalid port nope")
+}
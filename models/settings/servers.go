@@ -0,0 +1,147 @@
+package settings
+
+import (
+	"fmt"
+	"net/http"
+)
+
+const (
+	// AllServers selects every configured server
+	AllServers = "all"
+
+	BackendOllama = "ollama"
+	BackendOpenAI = "openai"
+)
+
+// Server is a named Ollama server, for the commands that query several
+// servers at once. Backend overrides the one in the settings
+type Server struct {
+	Name    string `json:"name"`
+	Url     string `json:"url"`
+	Backend string `json:"backend"`
+	Auth    *Auth  `json:"auth"`
+}
+
+// CheckBackend checks the backend of the settings and of every server is
+// one we know, an empty one is the Ollama api
+func (s *Settings) CheckBackend() error {
+	check := func(backend string) error {
+		switch backend {
+		case "", BackendOllama, BackendOpenAI:
+			return nil
+		}
+		return fmt.Errorf("unknown backend %s, use ollama or openai", backend)
+	}
+
+	if err := check(s.Backend); err != nil {
+		return err
+	}
+
+	for _, server := range s.Servers {
+		if err := check(server.Backend); err != nil {
+			return fmt.Errorf("server %s: %+v", server.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// SelectServers returns the servers with the given names, `all` selects
+// every configured server and names that aren't configured are taken as
+// addresses. The urls are normalized like OllamaUrl
+func (s *Settings) SelectServers(names []string) ([]Server, error) {
+	var (
+		selected = []Server{}
+		seen     = map[string]bool{}
+	)
+
+	add := func(server Server) error {
+		if seen[server.Name] {
+			return nil
+		}
+		seen[server.Name] = true
+
+		ollama_url, err := NormalizeHost(server.Url)
+		if err != nil {
+			return fmt.Errorf("server %s: %+v", server.Name, err)
+		}
+
+		selected = append(selected, Server{Name: server.Name, Url: ollama_url, Backend: server.Backend, Auth: server.Auth})
+		return nil
+	}
+
+	for _, name := range names {
+		if name == AllServers {
+			if len(s.Servers) == 0 {
+				return nil, fmt.Errorf("no servers configured")
+			}
+
+			for _, server := range s.Servers {
+				if err := add(server); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+
+		server := Server{Name: name, Url: name}
+		for _, configured := range s.Servers {
+			if configured.Name == name {
+				server = configured
+				break
+			}
+		}
+
+		if err := add(server); err != nil {
+			return nil, err
+		}
+	}
+
+	return selected, nil
+}
+
+// TransportWrapper is a transport around another one, that can be put
+// around a new transport too
+type TransportWrapper interface {
+	Wrap(transport http.RoundTripper) http.RoundTripper
+}
+
+// ForServer returns a copy of the settings to reach another server. The
+// transport is shared, unless either server is on a unix socket, as the
+// socket transport can only dial that socket. The credentials are only
+// sent to the server they're set for: its own auth, or the global one
+// when it's the ollamaurl server
+func (s *Settings) ForServer(server Server) (*Settings, error) {
+	cfg := *s
+	cfg.OllamaUrl, cfg.source = server.Url, "servers "+server.Name
+	if server.Backend != "" {
+		cfg.Backend = server.Backend
+	}
+
+	switch {
+	case server.Auth != nil:
+		cfg.Auth = *server.Auth
+		if err := cfg.Auth.ReadSecrets(); err != nil {
+			return nil, fmt.Errorf("server %s: %+v", server.Name, err)
+		}
+	case server.Url != s.OllamaUrl:
+		cfg.Auth = Auth{}
+	}
+
+	_, this_socket := s.unixSocket()
+	_, that_socket := cfg.unixSocket()
+	if this_socket || that_socket {
+		cfg.Transport = nil
+		if err := cfg.BuildTransport(); err != nil {
+			return nil, fmt.Errorf("server %s: %+v", server.Name, err)
+		}
+
+		// a wrapper around the transport, like --record or --replay, is
+		// kept around the new one
+		if w, ok := s.Transport.(TransportWrapper); ok {
+			cfg.Transport = w.Wrap(cfg.Transport)
+		}
+	}
+
+	return &cfg, nil
+}
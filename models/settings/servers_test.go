@@ -0,0 +1,128 @@
+package settings
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSettings_SelectServers(t *testing.T) {
+	var (
+		s = &Settings{Servers: []Server{
+			{Name: "gpu-1", Url: "gpu-1.internal"},
+			{Name: "gpu-2", Url: "http://gpu-2.internal:8080"},
+		}}
+		tests = []struct {
+			name    string
+			names   []string
+			want    []Server
+			wantErr string
+		}{
+			{
+				name:  "all",
+				names: []string{AllServers},
+				want: []Server{
+					{Name: "gpu-1", Url: "http://gpu-1.internal:11434"},
+					{Name: "gpu-2", Url: "http://gpu-2.internal:8080"},
+				},
+			},
+			{
+				name:  "by-name-and-address",
+				names: []string{"gpu-2", "10.0.0.5", "gpu-2"},
+				want: []Server{
+					{Name: "gpu-2", Url: "http://gpu-2.internal:8080"},
+					{Name: "10.0.0.5", Url: "http://10.0.0.5:11434"},
+				},
+			},
+			{
+				name:    "bad-address",
+				names:   []string{"gpu-3:port"},
+				wantErr: "server gpu-3:port: invalid port port",
+			},
+		}
+	)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.SelectServers(tt.names)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+
+	t.Run("none-configured", func(t *testing.T) {
+		_, err := (&Settings{}).SelectServers([]string{AllServers})
+		assert.ErrorContains(t, err, "no servers configured")
+	})
+}
+
+// wrapper stands for the --record transport
+type wrapper struct {
+	http.RoundTripper
+}
+
+func (w *wrapper) Wrap(transport http.RoundTripper) http.RoundTripper {
+	return &wrapper{RoundTripper: transport}
+}
+
+func TestSettings_ForServer(t *testing.T) {
+	s := &Settings{OllamaUrl: "http://localhost:11434", Auth: Auth{Token: "secret-token", Headers: map[string]string{"X-Api-Key": "secret-key"}}}
+	if !assert.NoError(t, s.BuildTransport()) {
+		return
+	}
+
+	t.Run("auth", func(t *testing.T) {
+		got, err := s.ForServer(Server{Name: "typed", Url: "http://typed.example:11434"})
+		if assert.NoError(t, err) {
+			assert.Equal(t, Auth{}, got.Auth, "no credentials for other hosts")
+		}
+
+		got, err = s.ForServer(Server{Name: "default", Url: "http://localhost:11434"})
+		if assert.NoError(t, err) {
+			assert.Equal(t, s.Auth, got.Auth, "the ollamaurl server keeps them")
+		}
+
+		got, err = s.ForServer(Server{Name: "gpu-1", Url: "http://gpu-1.internal:11434", Auth: &Auth{Username: "ml", Password: "pass"}})
+		if assert.NoError(t, err) {
+			assert.Equal(t, Auth{Username: "ml", Password: "pass"}, got.Auth)
+		}
+
+		_, err = s.ForServer(Server{Name: "gpu-2", Url: "http://gpu-2.internal:11434", Auth: &Auth{Token: "t", Username: "ml"}})
+		assert.ErrorContains(t, err, "server gpu-2: both a token and a username are set")
+	})
+
+	t.Run("shared-transport", func(t *testing.T) {
+		got, err := s.ForServer(Server{Name: "gpu-1", Url: "http://gpu-1.internal:11434"})
+		if assert.NoError(t, err) {
+			assert.Equal(t, "http://gpu-1.internal:11434", got.OllamaUrl)
+			assert.Equal(t, "servers gpu-1", got.OllamaUrlSource())
+			assert.Same(t, s.Transport, got.Transport)
+			assert.Equal(t, "http://localhost:11434", s.OllamaUrl)
+		}
+	})
+
+	t.Run("unix-socket", func(t *testing.T) {
+		got, err := s.ForServer(Server{Name: "local", Url: "unix:///run/ollama.sock"})
+		if assert.NoError(t, err) {
+			assert.NotSame(t, s.Transport, got.Transport)
+			assert.Equal(t, "http://localhost", got.ApiUrl())
+		}
+	})
+
+	t.Run("unix-socket-wrapped", func(t *testing.T) {
+		wrapped := &Settings{OllamaUrl: s.OllamaUrl, Transport: &wrapper{RoundTripper: s.Transport}}
+
+		got, err := wrapped.ForServer(Server{Name: "local", Url: "unix:///run/ollama.sock"})
+		if assert.NoError(t, err) && assert.IsType(t, &wrapper{}, got.Transport) {
+			inner := got.Transport.(*wrapper).RoundTripper
+			assert.NotSame(t, s.Transport, inner, "wraps the socket transport")
+			assert.IsType(t, &http.Transport{}, inner)
+		}
+	})
+}
@@ -4,22 +4,161 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 type Settings struct {
-	OllamaUrl string `json:"ollamaurl"`
+	OllamaUrl string   `json:"ollamaurl"`
+	Backend   string   `json:"backend"`
+	Proxy     string   `json:"proxy"`
+	Hardware  Hardware `json:"hardware"`
+	Requests  Requests `json:"requests"`
+	Auth      Auth     `json:"auth"`
+	TLS       TLS      `json:"tls"`
+	Servers   []Server `json:"servers"`
+	Cache     Cache    `json:"cache"`
+	Verbose   bool     `json:"verbose"`
 	Transport http.RoundTripper
+
+	// source is where OllamaUrl came from
+	source string
+}
+
+// Hardware is the memory budget of the machine running the models, in GB
+type Hardware struct {
+	VRAM float64 `json:"vram"`
+	RAM  float64 `json:"ram"`
+}
+
+// Requests are the limits for the api calls. Timeout applies to every
+// attempt, idempotent calls are retried up to Retries times waiting from
+// RetryWait, doubling up to RetryMaxWait, and MaxElapsed bounds them all
+type Requests struct {
+	Timeout      time.Duration `json:"timeout"`
+	Retries      int           `json:"retries"`
+	RetryWait    time.Duration `json:"retrywait"`
+	RetryMaxWait time.Duration `json:"retrymaxwait"`
+	MaxElapsed   time.Duration `json:"maxelapsed"`
+}
+
+// Cache is where the `/api/show` results are kept, Dir defaults to the
+// ollama-tools folder in the user cache directory
+type Cache struct {
+	Dir      string `json:"dir"`
+	Disabled bool   `json:"disabled"`
+}
+
+// Auth is how requests authenticate with a proxy in front of the server,
+// either a bearer token or basic auth, plus any extra headers. The token
+// and the password can be read from a file to keep them out of the config
+type Auth struct {
+	Token        string            `json:"token"`
+	TokenFile    string            `json:"tokenfile"`
+	Username     string            `json:"username"`
+	Password     string            `json:"password"`
+	PasswordFile string            `json:"passwordfile"`
+	Headers      map[string]string `json:"headers"`
+}
+
+// ReadSecrets reads the token and password files, if set, and checks only
+// one way to authenticate is used
+func (a *Auth) ReadSecrets() error {
+	var err error
+
+	if a.Token, err = readSecret("token", a.Token, a.TokenFile); err != nil {
+		return err
+	}
+
+	if a.Password, err = readSecret("password", a.Password, a.PasswordFile); err != nil {
+		return err
+	}
+
+	if a.Token != "" && a.Username != "" {
+		return fmt.Errorf("both a token and a username are set, use only one")
+	}
+
+	return nil
+}
+
+func readSecret(name string, value string, file string) (string, error) {
+	if file == "" {
+		return value, nil
+	}
+
+	if value != "" {
+		return "", fmt.Errorf("both a %s and a %s file are set, use only one", name, name)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", fmt.Errorf("reading %s file: %+v", name, err)
+	}
+
+	return strings.TrimSpace(string(data)), nil
+}
+
+// ParseHeader splits a header given as `Name: value`
+func ParseHeader(header string) (string, string, error) {
+	name, value, ok := strings.Cut(header, ":")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return "", "", fmt.Errorf("invalid header %q, use 'Name: value'", header)
+	}
+
+	return name, strings.TrimSpace(value), nil
 }
 
 func (s *Settings) Show() {
-	b, err := json.MarshalIndent(s, "", "  ")
+	fmt.Printf("Settings:\n%s\n", s.shown())
+}
+
+// shown returns the settings as json without the secrets, headers usually
+// carry api keys so their values are hidden too
+func (s *Settings) shown() string {
+	shown := *s
+	shown.Auth = shown.Auth.redacted()
+
+	shown.Servers = make([]Server, len(s.Servers))
+	for i, server := range s.Servers {
+		if server.Auth != nil {
+			auth := server.Auth.redacted()
+			server.Auth = &auth
+		}
+		shown.Servers[i] = server
+	}
+
+	b, err := json.MarshalIndent(&shown, "", "  ")
 	if err != nil {
-		fmt.Println("error:", err)
+		return fmt.Sprintf("error: %+v", err)
+	}
+
+	return string(b)
+}
+
+// redacted returns a copy of a with the secrets masked
+func (a Auth) redacted() Auth {
+	const mask = "********"
+
+	if a.Token != "" {
+		a.Token = mask
+	}
+	if a.Password != "" {
+		a.Password = mask
+	}
+
+	if len(a.Headers) > 0 {
+		headers := make(map[string]string, len(a.Headers))
+		for name := range a.Headers {
+			headers[name] = mask
+		}
+		a.Headers = headers
 	}
 
-	fmt.Printf("Settings:\n%s\n", string(b))
+	return a
 }
 
 func (s *Settings) ShowKeyValuePairs() {
@@ -27,6 +166,13 @@ func (s *Settings) ShowKeyValuePairs() {
 	// print all the keys
 	for _, key := range viper.AllKeys() {
 		val := viper.Get(key)
+		switch {
+		case key == "auth.token", key == "auth.password", strings.HasPrefix(key, "auth.headers."):
+			val = "********"
+		case key == "servers":
+			// they can have their own auth, Show lists them without it
+			continue
+		}
 		fmt.Printf("  %s: %v\n", key, val)
 	}
 }
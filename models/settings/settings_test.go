@@ -0,0 +1,97 @@
+package settings
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuth_ReadSecrets(t *testing.T) {
+	var (
+		dir    = t.TempDir()
+		secret = filepath.Join(dir, "secret")
+	)
+
+	_ = os.WriteFile(secret, []byte("s3cret\n"), 0o600)
+
+	tests := []struct {
+		name    string
+		auth    Auth
+		want    Auth
+		wantErr string
+	}{
+		{name: "token", auth: Auth{Token: "abc"}, want: Auth{Token: "abc"}},
+		{name: "token-file", auth: Auth{TokenFile: secret}, want: Auth{Token: "s3cret", TokenFile: secret}},
+		{name: "password-file", auth: Auth{Username: "pato", PasswordFile: secret}, want: Auth{Username: "pato", Password: "s3cret", PasswordFile: secret}},
+		{name: "token-and-file", auth: Auth{Token: "abc", TokenFile: secret}, wantErr: "both a token and a token file are set"},
+		{name: "missing-file", auth: Auth{PasswordFile: filepath.Join(dir, "nope")}, wantErr: "reading password file"},
+		{name: "token-and-username", auth: Auth{Token: "abc", Username: "pato"}, wantErr: "both a token and a username are set"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.auth.ReadSecrets()
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.want, tt.auth)
+			}
+		})
+	}
+}
+
+func TestParseHeader(t *testing.T) {
+	tests := []struct {
+		header    string
+		wantName  string
+		wantValue string
+		wantErr   bool
+	}{
+		{header: "X-Team: ml", wantName: "X-Team", wantValue: "ml"},
+		{header: "X-Forwarded-Host:ollama.internal:443", wantName: "X-Forwarded-Host", wantValue: "ollama.internal:443"},
+		{header: "X-Empty:", wantName: "X-Empty"},
+		{header: "no-colon", wantErr: true},
+		{header: ": value", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			name, value, err := ParseHeader(tt.header)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			if assert.NoError(t, err) {
+				assert.Equal(t, tt.wantName, name)
+				assert.Equal(t, tt.wantValue, value)
+			}
+		})
+	}
+}
+
+func TestSettings_shown(t *testing.T) {
+	s := &Settings{
+		OllamaUrl: "http://ollama:11434",
+		Auth:      Auth{Token: "abc", Headers: map[string]string{"X-Api-Key": "k3y"}},
+		Servers: []Server{
+			{Name: "vllm", Url: "http://vllm:8000", Auth: &Auth{Username: "pato", Password: "s3cret", Headers: map[string]string{"X-Team": "ml"}}},
+		},
+	}
+
+	got := s.shown()
+	assert.Contains(t, got, `"X-Api-Key": "********"`)
+	assert.Contains(t, got, `"X-Team": "********"`)
+	assert.Contains(t, got, `"username": "pato"`)
+	for _, secret := range []string{"abc", "k3y", "s3cret", `"ml"`} {
+		assert.NotContains(t, got, secret)
+	}
+
+	// the settings in use keep their values
+	assert.Equal(t, "k3y", s.Auth.Headers["X-Api-Key"])
+	assert.Equal(t, "s3cret", s.Servers[0].Auth.Password)
+	assert.Equal(t, "ml", s.Servers[0].Auth.Headers["X-Team"])
+}
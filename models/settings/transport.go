@@ -0,0 +1,155 @@
+package settings
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// TLS is how to trust and authenticate with servers using a private CA or
+// mutual TLS. CAFile is a PEM bundle added to the system roots
+type TLS struct {
+	CAFile     string `json:"cafile"`
+	CertFile   string `json:"certfile"`
+	KeyFile    string `json:"keyfile"`
+	ServerName string `json:"servername"`
+	Insecure   bool   `json:"insecure"`
+}
+
+// IsEmpty is true when no TLS setting is given
+func (t *TLS) IsEmpty() bool {
+	return *t == TLS{}
+}
+
+// Config returns the tls.Config for the settings
+func (t *TLS) Config() (*tls.Config, error) {
+	cfg := &tls.Config{
+		ServerName:         t.ServerName,
+		InsecureSkipVerify: t.Insecure,
+	}
+
+	if t.CAFile != "" {
+		pem, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading CA bundle: %+v", err)
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no PEM certificates found in CA bundle %s", t.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		return nil, fmt.Errorf("a client certificate needs both a cert file and a key file")
+	}
+
+	if t.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading client certificate: %+v", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	return cfg, nil
+}
+
+// UnixSocketScheme is the OllamaUrl scheme of servers listening on a unix
+// socket, like `unix:///run/ollama.sock`
+const UnixSocketScheme = "unix"
+
+// unixSocketUrl is the base url requests get when they go to a socket, the
+// host is only used for the Host header
+const unixSocketUrl = "http://localhost"
+
+// ApiUrl returns the base url for the api client, servers on a unix socket
+// get a plain http url as the socket is dialed by the transport
+func (s *Settings) ApiUrl() string {
+	if _, ok := s.unixSocket(); ok {
+		return unixSocketUrl
+	}
+	return s.OllamaUrl
+}
+
+func (s *Settings) unixSocket() (string, bool) {
+	u, err := url.Parse(s.OllamaUrl)
+	if err != nil || u.Scheme != UnixSocketScheme {
+		return "", false
+	}
+
+	return u.Path, true
+}
+
+// BuildTransport sets Transport from the settings: a unix socket dialer
+// when OllamaUrl is `unix://`, the proxy, and the TLS settings. A
+// transport that is already set is kept
+func (s *Settings) BuildTransport() error {
+	if s.Transport != nil {
+		return nil
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if socket, ok := s.unixSocket(); ok {
+		if socket == "" {
+			return fmt.Errorf("no socket path in %s", s.OllamaUrl)
+		}
+
+		dialer := &net.Dialer{Timeout: 30 * time.Second}
+		transport.Proxy = nil
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return dialer.DialContext(ctx, "unix", socket)
+		}
+	} else {
+		proxy, err := s.proxyFunc()
+		if err != nil {
+			return err
+		}
+		transport.Proxy = proxy
+	}
+
+	if !s.TLS.IsEmpty() {
+		cfg, err := s.TLS.Config()
+		if err != nil {
+			return err
+		}
+		transport.TLSClientConfig = cfg
+	}
+
+	s.Transport = transport
+
+	return nil
+}
+
+// proxyFunc honors HTTP_PROXY, HTTPS_PROXY and NO_PROXY, the `proxy`
+// setting replaces the first two. Requests to localhost are never proxied
+func (s *Settings) proxyFunc() (func(*http.Request) (*url.URL, error), error) {
+	cfg := httpproxy.FromEnvironment()
+
+	if s.Proxy != "" {
+		if _, err := url.Parse(s.Proxy); err != nil {
+			return nil, fmt.Errorf("invalid proxy %s: %+v", s.Proxy, err)
+		}
+		cfg.HTTPProxy, cfg.HTTPSProxy = s.Proxy, s.Proxy
+	}
+
+	proxy := cfg.ProxyFunc()
+
+	return func(r *http.Request) (*url.URL, error) {
+		return proxy(r.URL)
+	}, nil
+}
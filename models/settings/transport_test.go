@@ -0,0 +1,257 @@
+package settings
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/padiazg/ollama-tools/models/ollama"
+	"github.com/padiazg/ollama-tools/models/ollama/ollamatest"
+	"github.com/stretchr/testify/assert"
+)
+
+// tagsHandler is a fake Ollama server without models
+var tagsHandler = ollamatest.New(&ollamatest.Fixture{})
+
+func writePEM(t *testing.T, path string, kind string, der []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: kind, Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// clientCertificate creates a CA and a client certificate signed by it, it
+// returns the CA pool for the server and the cert and key files
+func clientCertificate(t *testing.T, dir string) (*x509.CertPool, string, string) {
+	t.Helper()
+
+	var (
+		ca_key, _     = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		client_key, _ = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		ca_template   = &x509.Certificate{
+			SerialNumber:          big.NewInt(1),
+			Subject:               pkix.Name{CommonName: "test CA"},
+			NotBefore:             time.Now().Add(-time.Hour),
+			NotAfter:              time.Now().Add(time.Hour),
+			IsCA:                  true,
+			KeyUsage:              x509.KeyUsageCertSign,
+			BasicConstraintsValid: true,
+		}
+		client_template = &x509.Certificate{
+			SerialNumber: big.NewInt(2),
+			Subject:      pkix.Name{CommonName: "ollama-tools"},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		}
+	)
+
+	ca_der, err := x509.CreateCertificate(rand.Reader, ca_template, ca_template, &ca_key.PublicKey, ca_key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ca, _ := x509.ParseCertificate(ca_der)
+
+	client_der, err := x509.CreateCertificate(rand.Reader, client_template, ca, &client_key.PublicKey, ca_key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key_der, _ := x509.MarshalECPrivateKey(client_key)
+
+	var (
+		cert_file = filepath.Join(dir, "client.pem")
+		key_file  = filepath.Join(dir, "client-key.pem")
+		pool      = x509.NewCertPool()
+	)
+
+	writePEM(t, cert_file, "CERTIFICATE", client_der)
+	writePEM(t, key_file, "EC PRIVATE KEY", key_der)
+	pool.AddCert(ca)
+
+	return pool, cert_file, key_file
+}
+
+func TestSettings_BuildTransport(t *testing.T) {
+	var (
+		dir     = t.TempDir()
+		ca_file = filepath.Join(dir, "ca.pem")
+		ts      = httptest.NewUnstartedServer(tagsHandler)
+		mtls    = httptest.NewUnstartedServer(tagsHandler)
+		quiet   = log.New(io.Discard, "", 0)
+	)
+
+	// failed handshakes are expected, keep them out of the test output
+	ts.Config.ErrorLog, mtls.Config.ErrorLog = quiet, quiet
+	ts.StartTLS()
+	defer ts.Close()
+
+	writePEM(t, ca_file, "CERTIFICATE", ts.Certificate().Raw)
+	_ = os.WriteFile(filepath.Join(dir, "empty.pem"), []byte("not a certificate"), 0o600)
+
+	client_cas, cert_file, key_file := clientCertificate(t, dir)
+	mtls.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: client_cas}
+	mtls.StartTLS()
+	defer mtls.Close()
+
+	// the httptest certificate is valid for 127.0.0.1 and example.com
+	localhost := strings.Replace(ts.URL, "127.0.0.1", "localhost", 1)
+
+	tests := []struct {
+		name     string
+		url      string
+		tls      TLS
+		wantErr  string
+		buildErr string
+	}{
+		{name: "no-ca", url: ts.URL, wantErr: "signed by an unknown authority, set tls.cafile"},
+		{name: "ca-file", url: ts.URL, tls: TLS{CAFile: ca_file}},
+		{name: "insecure", url: ts.URL, tls: TLS{Insecure: true}},
+		{name: "wrong-host", url: localhost, tls: TLS{CAFile: ca_file}, wantErr: "not valid for localhost, set tls.servername"},
+		{name: "server-name", url: localhost, tls: TLS{CAFile: ca_file, ServerName: "example.com"}},
+		{name: "mtls-no-client-cert", url: mtls.URL, tls: TLS{CAFile: ca_file}, wantErr: "requires a client certificate"},
+		{name: "mtls", url: mtls.URL, tls: TLS{CAFile: ca_file, CertFile: cert_file, KeyFile: key_file}},
+		{name: "missing-ca", tls: TLS{CAFile: filepath.Join(dir, "nope.pem")}, buildErr: "reading CA bundle"},
+		{name: "empty-ca", tls: TLS{CAFile: filepath.Join(dir, "empty.pem")}, buildErr: "no PEM certificates found"},
+		{name: "cert-without-key", tls: TLS{CertFile: cert_file}, buildErr: "needs both a cert file and a key file"},
+		{name: "bad-key-pair", tls: TLS{CertFile: cert_file, KeyFile: ca_file}, buildErr: "loading client certificate"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Settings{TLS: tt.tls}
+
+			err := s.BuildTransport()
+			if tt.buildErr != "" {
+				assert.ErrorContains(t, err, tt.buildErr)
+				return
+			}
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			c := ollama.NewClient(tt.url, ollama.WithTransport(s.Transport))
+			defer c.Close()
+
+			_, err = c.Tags(context.Background())
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+
+	t.Run("keeps-transport", func(t *testing.T) {
+		transport := &http.Transport{}
+		s := &Settings{Transport: transport, TLS: TLS{Insecure: true}}
+		assert.NoError(t, s.BuildTransport())
+		assert.Same(t, transport, s.Transport)
+	})
+}
+
+func TestSettings_BuildTransport_unixSocket(t *testing.T) {
+	socket := filepath.Join(t.TempDir(), "ollama.sock")
+
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Skipf("unix sockets not available: %+v", err)
+	}
+
+	ts := httptest.NewUnstartedServer(tagsHandler)
+	ts.Listener = listener
+	ts.Start()
+	defer ts.Close()
+
+	s := &Settings{OllamaUrl: "unix://" + socket, Proxy: "http://proxy.invalid:3128"}
+	if !assert.NoError(t, s.BuildTransport()) {
+		return
+	}
+	assert.Equal(t, "http://localhost", s.ApiUrl())
+
+	c := ollama.NewClient(s.ApiUrl(), ollama.WithTransport(s.Transport))
+	defer c.Close()
+
+	_, err = c.Tags(context.Background())
+	assert.NoError(t, err, "the proxy is not used for sockets")
+
+	s = &Settings{OllamaUrl: "unix://"}
+	assert.ErrorContains(t, s.BuildTransport(), "no socket path")
+}
+
+func TestSettings_BuildTransport_proxy(t *testing.T) {
+	var (
+		proxied []string
+		proxy   = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// a proxy gets the absolute url of the target
+			proxied = append(proxied, r.URL.String())
+			tagsHandler.ServeHTTP(w, r)
+		}))
+	)
+	defer proxy.Close()
+
+	t.Setenv("HTTP_PROXY", "http://env-proxy.invalid:3128")
+	t.Setenv("NO_PROXY", "direct.internal")
+
+	s := &Settings{OllamaUrl: "http://ollama.internal:11434", Proxy: proxy.URL}
+	if !assert.NoError(t, s.BuildTransport()) {
+		return
+	}
+
+	c := ollama.NewClient(s.ApiUrl(), ollama.WithTransport(s.Transport))
+	defer c.Close()
+
+	_, err := c.Tags(context.Background())
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"http://ollama.internal:11434/api/tags"}, proxied)
+	}
+
+	tests := []struct {
+		name  string
+		proxy string
+		url   string
+		want  string
+	}{
+		{name: "setting", proxy: proxy.URL, url: "http://ollama.internal:11434", want: proxy.URL},
+		{name: "environment", url: "http://ollama.internal:11434", want: "http://env-proxy.invalid:3128"},
+		{name: "no-proxy", proxy: proxy.URL, url: "http://direct.internal:11434"},
+		{name: "localhost", proxy: proxy.URL, url: "http://localhost:11434"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Settings{OllamaUrl: tt.url, Proxy: tt.proxy}
+			if !assert.NoError(t, s.BuildTransport()) {
+				return
+			}
+
+			req, _ := http.NewRequest(http.MethodGet, tt.url+"/api/tags", nil)
+			got, err := s.Transport.(*http.Transport).Proxy(req)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			if tt.want == "" {
+				assert.Nil(t, got)
+			} else {
+				assert.Equal(t, tt.want, got.String())
+			}
+		})
+	}
+}
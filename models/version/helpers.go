@@ -1,6 +1,9 @@
 package version
 
-import "strconv"
+import (
+	"strconv"
+	"strings"
+)
 
 func parseInt(s string) int {
 	i, e := strconv.Atoi(s)
@@ -13,3 +16,60 @@ func parseInt(s string) int {
 func CurrentVersion() *VersionInfo {
 	return v
 }
+
+func sign(i int) int {
+	switch {
+	case i < 0:
+		return -1
+	case i > 0:
+		return 1
+	}
+	return 0
+}
+
+// preRelease drops the build metadata from the extra part of a version
+func preRelease(extra string) string {
+	pre, _, _ := strings.Cut(extra, "+")
+	return pre
+}
+
+// comparePreRelease compares the dot separated identifiers, numbers
+// numerically and everything else as text. No pre-release is greater than
+// any pre-release
+func comparePreRelease(a string, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+
+	var (
+		as = strings.Split(a, ".")
+		bs = strings.Split(b, ".")
+	)
+
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aerr := strconv.Atoi(as[i])
+		bn, berr := strconv.Atoi(bs[i])
+
+		switch {
+		case aerr == nil && berr == nil:
+			if an != bn {
+				return sign(an - bn)
+			}
+		case aerr == nil:
+			return -1
+		case berr == nil:
+			return 1
+		default:
+			if c := strings.Compare(as[i], bs[i]); c != 0 {
+				return c
+			}
+		}
+	}
+
+	return sign(len(as) - len(bs))
+}
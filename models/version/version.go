@@ -38,6 +38,38 @@ func (v *VersionInfo) ParseVersion() {
 	}
 }
 
+// Parse returns the version info of a version string like `0.5.7` or
+// `v0.6.0-rc1`, the parts that can't be read are left as 0
+func Parse(version string) *VersionInfo {
+	v := &VersionInfo{Version: version}
+	v.ParseVersion()
+	return v
+}
+
+// IsZero is true for versions that couldn't be read, and for `0.0.0`,
+// which is what development builds of ollama report
+func (v *VersionInfo) IsZero() bool {
+	return v.Major == 0 && v.Minor == 0 && v.Patch == 0
+}
+
+// Compare returns -1, 0 or 1 as v is lower, equal or greater than other,
+// following semver precedence: a pre-release like `0.6.0-rc1` is lower than
+// `0.6.0`, and build metadata after a `+` is ignored
+func (v *VersionInfo) Compare(other *VersionInfo) int {
+	for _, d := range [][2]int{{v.Major, other.Major}, {v.Minor, other.Minor}, {v.Patch, other.Patch}} {
+		if d[0] != d[1] {
+			return sign(d[0] - d[1])
+		}
+	}
+
+	return comparePreRelease(preRelease(v.Extra), preRelease(other.Extra))
+}
+
+// AtLeast is true when v is equal or greater than other
+func (v *VersionInfo) AtLeast(other *VersionInfo) bool {
+	return v.Compare(other) >= 0
+}
+
 func (v *VersionInfo) ParseDate() error {
 	ts, err := time.Parse(time.RFC3339, v.BuildDate)
 	if err != nil {
@@ -201,3 +201,36 @@ func TestVersion_String(t *testing.T) {
 		})
 	}
 }
+
+func TestVersion_Compare(t *testing.T) {
+	tests := []struct {
+		a    string
+		b    string
+		want int
+	}{
+		{a: "0.5.7", b: "0.5.7", want: 0},
+		{a: "v0.5.7", b: "0.5.7", want: 0},
+		{a: "0.5.7", b: "0.5.10", want: -1},
+		{a: "0.10.0", b: "0.9.9", want: 1},
+		{a: "1.0.0", b: "0.99.99", want: 1},
+		{a: "0.6", b: "0.6.0", want: 0},
+		{a: "0.6.0-rc1", b: "0.6.0", want: -1},
+		{a: "0.6.0", b: "0.6.0-rc1", want: 1},
+		{a: "0.6.0-rc.2", b: "0.6.0-rc.10", want: -1},
+		{a: "0.6.0-alpha", b: "0.6.0-alpha.1", want: -1},
+		{a: "0.6.0-alpha.1", b: "0.6.0-beta", want: -1},
+		{a: "0.6.0-1", b: "0.6.0-alpha", want: -1},
+		{a: "0.6.0+build.5", b: "0.6.0", want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+"_"+tt.b, func(t *testing.T) {
+			if got := Parse(tt.a).Compare(Parse(tt.b)); got != tt.want {
+				t.Errorf("Version.Compare(%s, %s) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+
+			if got := Parse(tt.a).AtLeast(Parse(tt.b)); got != (tt.want >= 0) {
+				t.Errorf("Version.AtLeast(%s, %s) = %v, want %v", tt.a, tt.b, got, tt.want >= 0)
+			}
+		})
+	}
+}